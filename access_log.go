@@ -0,0 +1,234 @@
+package giraffe
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RequestIDHeader is the header that carries the request ID
+const RequestIDHeader = "X-Request-ID"
+
+// AccessLogEntry describes a served HTTP request
+type AccessLogEntry struct {
+	// Time when the request was received
+	Time time.Time
+	// Method of the request
+	Method string
+	// Path of the request URL
+	Path string
+	// Query of the request URL
+	Query string
+	// Proto is the protocol of the request
+	Proto string
+	// Status code of the response
+	Status int
+	// Latency of the request processing
+	Latency time.Duration
+	// FirstByte is the time to the first byte of the response
+	FirstByte time.Duration
+	// Bytes written into the response body
+	Bytes int64
+	// RemoteIP is the IP address of the client
+	RemoteIP string
+	// UserAgent of the client
+	UserAgent string
+	// Referer of the request
+	Referer string
+	// RequestID that identifies the request
+	RequestID string
+	// User is the name of the authenticated user
+	User string
+	// RequestHeader is the header of the request
+	RequestHeader http.Header
+	// ResponseHeader is the header of the response
+	ResponseHeader http.Header
+}
+
+func newAccessLogEntry(request *http.Request, writer ResponseWriter) *AccessLogEntry {
+	requestID := RequestID(request.Context())
+	if requestID == "" {
+		requestID = responseRequestID(writer)
+	}
+	if requestID == "" {
+		requestID = request.Header.Get(RequestIDHeader)
+	}
+
+	user, _, _ := request.BasicAuth()
+	if user == "" && request.URL.User != nil {
+		user = request.URL.User.Username()
+	}
+
+	return &AccessLogEntry{
+		Time:           writer.Start(),
+		Method:         request.Method,
+		Path:           request.URL.Path,
+		Query:          request.URL.RawQuery,
+		Proto:          request.Proto,
+		Status:         writer.Status(),
+		Latency:        time.Since(writer.Start()),
+		FirstByte:      writer.TimeToFirstByte(),
+		Bytes:          writer.Size(),
+		RemoteIP:       clientIP(request),
+		UserAgent:      request.UserAgent(),
+		Referer:        request.Referer(),
+		RequestID:      requestID,
+		User:           user,
+		RequestHeader:  request.Header,
+		ResponseHeader: writer.Header(),
+	}
+}
+
+// AccessLogFormatter formats the access log entries
+type AccessLogFormatter interface {
+	Format(entry *AccessLogEntry) string
+}
+
+// AccessLogFormatterFunc converts a func into AccessLogFormatter
+type AccessLogFormatterFunc func(entry *AccessLogEntry) string
+
+// Format formats an access log entry
+func (f AccessLogFormatterFunc) Format(entry *AccessLogEntry) string {
+	return f(entry)
+}
+
+// TextFormatter formats the access log entries as human readable text
+type TextFormatter struct {
+	// Color enables the colors of status codes and methods
+	Color bool
+}
+
+// Format formats an access log entry
+func (formatter *TextFormatter) Format(entry *AccessLogEntry) string {
+	var (
+		statusColor string
+		methodColor string
+		resetColor  string
+	)
+
+	if formatter.Color {
+		statusColor = colorForStatus(entry.Status)
+		methodColor = colorForMethod(entry.Method)
+		resetColor = DefaultColor
+	}
+
+	return fmt.Sprintf("%s %3d %s| %13v | %s |%s  %s %-7s %s",
+		statusColor, entry.Status, resetColor,
+		entry.Latency,
+		entry.RemoteIP,
+		methodColor, resetColor, entry.Method,
+		entry.Path,
+	)
+}
+
+// JSONFormatter formats the access log entries as JSON lines
+type JSONFormatter struct{}
+
+// Format formats an access log entry
+func (formatter *JSONFormatter) Format(entry *AccessLogEntry) string {
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+
+	encoder.Encode(&struct {
+		Time      string  `json:"time"`
+		Method    string  `json:"method"`
+		Path      string  `json:"path"`
+		Query     string  `json:"query,omitempty"`
+		Proto     string  `json:"proto,omitempty"`
+		Status    int     `json:"status"`
+		Latency   float64 `json:"latency_ms"`
+		FirstByte float64 `json:"ttfb_ms"`
+		Bytes     int64   `json:"bytes"`
+		RemoteIP  string  `json:"remote_ip"`
+		UserAgent string  `json:"user_agent,omitempty"`
+		Referer   string  `json:"referer,omitempty"`
+		RequestID string  `json:"request_id,omitempty"`
+	}{
+		Time:      entry.Time.Format(time.RFC3339Nano),
+		Method:    entry.Method,
+		Path:      entry.Path,
+		Query:     entry.Query,
+		Proto:     entry.Proto,
+		Status:    entry.Status,
+		Latency:   milliseconds(entry.Latency),
+		FirstByte: milliseconds(entry.FirstByte),
+		Bytes:     entry.Bytes,
+		RemoteIP:  entry.RemoteIP,
+		UserAgent: entry.UserAgent,
+		Referer:   entry.Referer,
+		RequestID: entry.RequestID,
+	})
+
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
+
+// LogfmtFormatter formats the access log entries as logfmt key/value pairs
+type LogfmtFormatter struct{}
+
+// Format formats an access log entry
+func (formatter *LogfmtFormatter) Format(entry *AccessLogEntry) string {
+	return logfmt(entry.Fields()...)
+}
+
+// Fields returns the entry as alternating keys and values
+func (entry *AccessLogEntry) Fields() []interface{} {
+	return []interface{}{
+		"time", entry.Time.Format(time.RFC3339Nano),
+		"method", entry.Method,
+		"path", entry.Path,
+		"query", entry.Query,
+		"proto", entry.Proto,
+		"status", entry.Status,
+		"latency_ms", milliseconds(entry.Latency),
+		"ttfb_ms", milliseconds(entry.FirstByte),
+		"bytes", entry.Bytes,
+		"remote_ip", entry.RemoteIP,
+		"user_agent", entry.UserAgent,
+		"referer", entry.Referer,
+		"request_id", entry.RequestID,
+	}
+}
+
+func logfmt(keyvals ...interface{}) string {
+	pairs := make([]string, 0, (len(keyvals)+1)/2)
+	for index := 0; index < len(keyvals); index += 2 {
+		var value interface{}
+		if index+1 < len(keyvals) {
+			value = keyvals[index+1]
+		}
+		pairs = append(pairs, fmt.Sprint(keyvals[index])+"="+logfmtValue(value))
+	}
+	return strings.Join(pairs, " ")
+}
+
+func logfmtValue(model interface{}) string {
+	var value string
+	switch v := model.(type) {
+	case string:
+		value = v
+	case float64:
+		value = strconv.FormatFloat(v, 'f', -1, 64)
+	case error:
+		value = v.Error()
+	default:
+		value = fmt.Sprint(model)
+	}
+
+	if value == "" {
+		return `""`
+	}
+
+	if strings.ContainsAny(value, " =\"\\") || strconv.Quote(value) != `"`+value+`"` {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+func milliseconds(duration time.Duration) float64 {
+	return float64(duration) / float64(time.Millisecond)
+}
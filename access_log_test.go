@@ -0,0 +1,80 @@
+package giraffe_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/svett/giraffe"
+)
+
+var _ = Describe("AccessLogFormatter", func() {
+	var entry *giraffe.AccessLogEntry
+
+	BeforeEach(func() {
+		entry = &giraffe.AccessLogEntry{
+			Time:      time.Date(2016, time.July, 3, 10, 20, 30, 0, time.UTC),
+			Method:    "POST",
+			Path:      "/users",
+			Query:     "page=2",
+			Proto:     "HTTP/1.1",
+			Status:    201,
+			Latency:   1500 * time.Microsecond,
+			FirstByte: 500 * time.Microsecond,
+			Bytes:     42,
+			RemoteIP:  "10.0.0.1",
+			UserAgent: "curl/7.47.0",
+			Referer:   "http://example.com/",
+			RequestID: "abc",
+		}
+	})
+
+	Describe("TextFormatter", func() {
+		It("formats the entry as text", func() {
+			msg := (&giraffe.TextFormatter{}).Format(entry)
+			Expect(msg).To(ContainSubstring("201"))
+			Expect(msg).To(ContainSubstring("POST"))
+			Expect(msg).To(ContainSubstring("/users"))
+			Expect(msg).To(ContainSubstring("10.0.0.1"))
+		})
+
+		It("colors the status and method", func() {
+			msg := (&giraffe.TextFormatter{Color: true}).Format(entry)
+			Expect(msg).To(ContainSubstring(giraffe.ColorGreen))
+			Expect(msg).To(ContainSubstring(giraffe.ColorCyan))
+		})
+	})
+
+	Describe("JSONFormatter", func() {
+		It("formats the entry as JSON", func() {
+			Expect((&giraffe.JSONFormatter{}).Format(entry)).To(MatchJSON(`{
+				"time": "2016-07-03T10:20:30Z",
+				"method": "POST",
+				"path": "/users",
+				"query": "page=2",
+				"proto": "HTTP/1.1",
+				"status": 201,
+				"latency_ms": 1.5,
+				"ttfb_ms": 0.5,
+				"bytes": 42,
+				"remote_ip": "10.0.0.1",
+				"user_agent": "curl/7.47.0",
+				"referer": "http://example.com/",
+				"request_id": "abc"
+			}`))
+		})
+	})
+
+	Describe("LogfmtFormatter", func() {
+		It("formats the entry as key/value pairs", func() {
+			entry.UserAgent = "Mozilla/5.0 (X11)"
+			entry.RequestID = ""
+
+			Expect((&giraffe.LogfmtFormatter{}).Format(entry)).To(Equal(
+				`time=2016-07-03T10:20:30Z method=POST path=/users query="page=2" proto=HTTP/1.1 status=201 ` +
+					`latency_ms=1.5 ttfb_ms=0.5 bytes=42 remote_ip=10.0.0.1 user_agent="Mozilla/5.0 (X11)" referer=http://example.com/ request_id=""`,
+			))
+		})
+	})
+})
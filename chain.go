@@ -0,0 +1,68 @@
+package giraffe
+
+import "net/http"
+
+// Middleware wraps a http.Handler
+type Middleware func(http.Handler) http.Handler
+
+// Middleware converts the HandlerFunc into Middleware
+func (f HandlerFunc) Middleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+			f(w, request, next.ServeHTTP)
+		})
+	}
+}
+
+// Chain composes middlewares into a http.Handler. The middlewares are
+// executed in the order in which they are added to the chain.
+type Chain struct {
+	middlewares []Middleware
+}
+
+// NewChain creates a new chain of HandlerFuncs
+func NewChain(handlers ...HandlerFunc) *Chain {
+	return (&Chain{}).Use(handlers...)
+}
+
+// Use returns a new chain that executes the HandlerFuncs after the middlewares of the chain
+func (chain *Chain) Use(handlers ...HandlerFunc) *Chain {
+	middlewares := make([]Middleware, len(handlers))
+	for index, handler := range handlers {
+		middlewares[index] = handler.Middleware()
+	}
+	return chain.UseMiddleware(middlewares...)
+}
+
+// UseMiddleware returns a new chain that executes the http.Handler middlewares after the middlewares of the chain
+func (chain *Chain) UseMiddleware(middlewares ...Middleware) *Chain {
+	combined := make([]Middleware, 0, len(chain.middlewares)+len(middlewares))
+	combined = append(combined, chain.middlewares...)
+	combined = append(combined, middlewares...)
+	return &Chain{middlewares: combined}
+}
+
+// Extend returns a new chain that executes the middlewares of another chain after the middlewares of the chain
+func (chain *Chain) Extend(other *Chain) *Chain {
+	return chain.UseMiddleware(other.middlewares...)
+}
+
+// Then composes the chain with the final handler. http.NotFoundHandler is used when the handler is nil.
+func (chain *Chain) Then(handler http.Handler) http.Handler {
+	if handler == nil {
+		handler = http.NotFoundHandler()
+	}
+
+	for index := len(chain.middlewares) - 1; index >= 0; index-- {
+		handler = chain.middlewares[index](handler)
+	}
+	return handler
+}
+
+// ThenFunc composes the chain with the final handler func
+func (chain *Chain) ThenFunc(handler http.HandlerFunc) http.Handler {
+	if handler == nil {
+		return chain.Then(nil)
+	}
+	return chain.Then(handler)
+}
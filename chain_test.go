@@ -0,0 +1,97 @@
+package giraffe_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/svett/giraffe"
+)
+
+var _ = Describe("Chain", func() {
+	var (
+		calls    []string
+		recorder *httptest.ResponseRecorder
+		request  *http.Request
+	)
+
+	handlerFunc := func(name string) giraffe.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			calls = append(calls, name)
+			next(w, r)
+		}
+	}
+
+	middleware := func(name string) giraffe.Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	final := func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "handler")
+		w.WriteHeader(http.StatusAccepted)
+	}
+
+	BeforeEach(func() {
+		calls = []string{}
+		recorder = httptest.NewRecorder()
+		request = httptest.NewRequest("GET", "http://example.com/api/users", nil)
+	})
+
+	It("executes the middlewares in order", func() {
+		chain := giraffe.NewChain(handlerFunc("first"), handlerFunc("second")).UseMiddleware(middleware("third"))
+		chain.ThenFunc(final).ServeHTTP(recorder, request)
+
+		Expect(calls).To(Equal([]string{"first", "second", "third", "handler"}))
+		Expect(recorder.Code).To(Equal(http.StatusAccepted))
+	})
+
+	It("stops when a middleware does not call the next handler", func() {
+		stop := func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+
+		giraffe.NewChain(handlerFunc("first"), stop, handlerFunc("second")).ThenFunc(final).ServeHTTP(recorder, request)
+
+		Expect(calls).To(Equal([]string{"first"}))
+		Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("does not change the chain when it is extended", func() {
+		base := giraffe.NewChain(handlerFunc("base"))
+		api := base.Use(handlerFunc("api"))
+		admin := base.Extend(giraffe.NewChain(handlerFunc("admin")))
+
+		base.ThenFunc(final).ServeHTTP(recorder, request)
+		Expect(calls).To(Equal([]string{"base", "handler"}))
+
+		calls = []string{}
+		api.ThenFunc(final).ServeHTTP(recorder, request)
+		Expect(calls).To(Equal([]string{"base", "api", "handler"}))
+
+		calls = []string{}
+		admin.ThenFunc(final).ServeHTTP(recorder, request)
+		Expect(calls).To(Equal([]string{"base", "admin", "handler"}))
+	})
+
+	It("can be mounted on ServeMux", func() {
+		mux := http.NewServeMux()
+		mux.Handle("/api/", giraffe.NewChain(handlerFunc("api")).ThenFunc(final))
+
+		giraffe.NewChain(handlerFunc("root")).Then(mux).ServeHTTP(recorder, request)
+		Expect(calls).To(Equal([]string{"root", "api", "handler"}))
+	})
+
+	Context("when the final handler is nil", func() {
+		It("responds with not found", func() {
+			giraffe.NewChain(handlerFunc("first")).Then(nil).ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+})
@@ -0,0 +1,189 @@
+package giraffe
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+const (
+	// ForwardedHeader is the RFC 7239 header that discloses the proxies
+	ForwardedHeader = "Forwarded"
+	// XForwardedForHeader is the de facto standard header that discloses the proxies
+	XForwardedForHeader = "X-Forwarded-For"
+	// XRealIPHeader is the header that carries the client IP set by the proxy
+	XRealIPHeader = "X-Real-IP"
+)
+
+type clientIPKey struct{}
+
+// IPResolver resolves the IP address of the client. The proxy headers
+// Forwarded, X-Forwarded-For and X-Real-IP are honoured only when the
+// request is received from a trusted proxy.
+type IPResolver struct {
+	trusted []*net.IPNet
+}
+
+// NewIPResolver creates a new IPResolver that trusts the proxies in given CIDRs.
+// A single IP address is accepted as well.
+func NewIPResolver(trustedCIDRs ...string) (*IPResolver, error) {
+	resolver := &IPResolver{}
+
+	for _, cidr := range trustedCIDRs {
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, fmt.Errorf("Invalid trusted proxy '%s'", cidr)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			resolver.trusted = append(resolver.trusted, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid trusted proxy '%s': %s", cidr, err)
+		}
+		resolver.trusted = append(resolver.trusted, network)
+	}
+
+	return resolver, nil
+}
+
+// Resolve returns the IP address of the client that sent the request
+func (resolver *IPResolver) Resolve(request *http.Request) string {
+	remote := remoteIP(request.RemoteAddr)
+
+	ip := net.ParseIP(remote)
+	if ip == nil || !resolver.isTrusted(ip) {
+		return remote
+	}
+
+	if forwarded := forwardedFor(request.Header.Values(ForwardedHeader)); len(forwarded) > 0 {
+		return resolver.resolveChain(forwarded, remote)
+	}
+
+	if forwarded := split(request.Header.Values(XForwardedForHeader)); len(forwarded) > 0 {
+		return resolver.resolveChain(forwarded, remote)
+	}
+
+	if ip := parseIP(request.Header.Get(XRealIPHeader)); ip != nil {
+		return ip.String()
+	}
+
+	return remote
+}
+
+// resolveChain walks the proxy chain from right to left and returns the
+// first address that is not trusted
+func (resolver *IPResolver) resolveChain(chain []string, remote string) string {
+	client := remote
+
+	for index := len(chain) - 1; index >= 0; index-- {
+		ip := parseIP(chain[index])
+		if ip == nil {
+			break
+		}
+
+		client = ip.String()
+		if !resolver.isTrusted(ip) {
+			break
+		}
+	}
+
+	return client
+}
+
+func (resolver *IPResolver) isTrusted(ip net.IP) bool {
+	for _, network := range resolver.trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewHTTPClientIP resolves the IP address of the client and stores it in the request context
+func NewHTTPClientIP(resolver *IPResolver) HandlerFunc {
+	return func(w http.ResponseWriter, request *http.Request, next http.HandlerFunc) {
+		ip := resolver.Resolve(request)
+		next(w, request.WithContext(WithClientIP(request.Context(), ip)))
+	}
+}
+
+// WithClientIP returns a copy of the context that carries the client IP
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIP returns the client IP stored in the context
+func ClientIP(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
+
+func clientIP(request *http.Request) string {
+	if ip := ClientIP(request.Context()); ip != "" {
+		return ip
+	}
+	return remoteIP(request.RemoteAddr)
+}
+
+// remoteIP strips the port of the remote address
+func remoteIP(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// parseIP parses an address of a proxy header that may contain port or brackets
+func parseIP(addr string) net.IP {
+	addr = strings.Trim(strings.TrimSpace(addr), `"`)
+
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+
+	return net.ParseIP(strings.Trim(addr, "[]"))
+}
+
+// forwardedFor returns the for parameters of RFC 7239 Forwarded header values
+func forwardedFor(values []string) []string {
+	addrs := []string{}
+
+	for _, element := range split(values) {
+		for _, pair := range strings.Split(element, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if ok && strings.EqualFold(key, "for") {
+				addrs = append(addrs, value)
+			}
+		}
+	}
+
+	return addrs
+}
+
+func split(values []string) []string {
+	items := []string{}
+
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+
+	return items
+}
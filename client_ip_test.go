@@ -0,0 +1,117 @@
+package giraffe_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/svett/giraffe"
+	"github.com/svett/giraffe/fakes"
+)
+
+var _ = Describe("IPResolver", func() {
+	var (
+		resolver *giraffe.IPResolver
+		request  *http.Request
+	)
+
+	BeforeEach(func() {
+		var err error
+		resolver, err = giraffe.NewIPResolver("10.0.0.0/8", "192.168.1.1", "2001:db8::/32")
+		Expect(err).NotTo(HaveOccurred())
+
+		request = httptest.NewRequest("GET", "http://example.com/", nil)
+		request.RemoteAddr = "10.0.0.1:4711"
+	})
+
+	It("returns an error when the trusted proxy is invalid", func() {
+		_, err := giraffe.NewIPResolver("10.0.0.0/33")
+		Expect(err).To(HaveOccurred())
+
+		_, err = giraffe.NewIPResolver("localhost")
+		Expect(err).To(MatchError("Invalid trusted proxy 'localhost'"))
+	})
+
+	It("strips the port of the remote address", func() {
+		Expect(resolver.Resolve(request)).To(Equal("10.0.0.1"))
+	})
+
+	It("resolves the client of X-Forwarded-For header", func() {
+		request.Header.Add("X-Forwarded-For", "203.0.113.7, 198.51.100.1")
+		request.Header.Add("X-Forwarded-For", "10.0.0.2")
+		Expect(resolver.Resolve(request)).To(Equal("198.51.100.1"))
+	})
+
+	It("resolves the leftmost address when all proxies are trusted", func() {
+		request.Header.Set("X-Forwarded-For", "192.168.1.1, 10.0.0.2")
+		Expect(resolver.Resolve(request)).To(Equal("192.168.1.1"))
+	})
+
+	It("resolves the client of Forwarded header", func() {
+		request.Header.Set("Forwarded", `for="[2001:db8:cafe::17]:4711";proto=https, For=192.168.1.1;by=10.0.0.1`)
+		request.Header.Set("X-Forwarded-For", "203.0.113.7")
+		Expect(resolver.Resolve(request)).To(Equal("2001:db8:cafe::17"))
+	})
+
+	It("resolves the client of X-Real-IP header", func() {
+		request.Header.Set("X-Real-IP", "203.0.113.7")
+		Expect(resolver.Resolve(request)).To(Equal("203.0.113.7"))
+	})
+
+	It("stops at an invalid address", func() {
+		request.Header.Set("X-Forwarded-For", "203.0.113.7, unknown, 10.0.0.2")
+		Expect(resolver.Resolve(request)).To(Equal("10.0.0.2"))
+	})
+
+	Context("when the request is not received from a trusted proxy", func() {
+		BeforeEach(func() {
+			request.RemoteAddr = "203.0.113.1:4711"
+		})
+
+		It("ignores the proxy headers", func() {
+			request.Header.Set("X-Forwarded-For", "198.51.100.1")
+			request.Header.Set("X-Real-IP", "198.51.100.1")
+			Expect(resolver.Resolve(request)).To(Equal("203.0.113.1"))
+		})
+	})
+
+	Describe("NewHTTPClientIP", func() {
+		It("stores the client IP in the request context", func() {
+			request.Header.Set("X-Real-IP", "203.0.113.7")
+
+			ip := ""
+			giraffe.NewHTTPClientIP(resolver)(httptest.NewRecorder(), request, func(w http.ResponseWriter, req *http.Request) {
+				ip = giraffe.ClientIP(req.Context())
+			})
+
+			Expect(ip).To(Equal("203.0.113.7"))
+			Expect(giraffe.ClientIP(context.Background())).To(BeEmpty())
+		})
+	})
+
+	Describe("HTTPLogger", func() {
+		var logger *fakes.FakeLogger
+
+		BeforeEach(func() {
+			logger = new(fakes.FakeLogger)
+			request.Header.Set("X-Forwarded-For", "203.0.113.7")
+		})
+
+		serve := func(options ...giraffe.LoggerOption) string {
+			options = append(options, giraffe.WithFormatter(giraffe.NewPatternFormatter("%a")))
+			giraffe.NewHTTPLogger(logger, false, options...)(httptest.NewRecorder(), request, func(w http.ResponseWriter, req *http.Request) {})
+			return logger.PrintlnArgsForCall(logger.PrintlnCallCount() - 1)[0].(string)
+		}
+
+		It("logs the remote address without port by default", func() {
+			Expect(serve()).To(Equal("10.0.0.1"))
+		})
+
+		It("logs the resolved client IP", func() {
+			Expect(serve(giraffe.WithIPResolver(resolver))).To(Equal("203.0.113.7"))
+		})
+	})
+})
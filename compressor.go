@@ -0,0 +1,376 @@
+package giraffe
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// DefaultCompressionMinSize is the minimal size of the response body that is compressed
+const DefaultCompressionMinSize = 1024
+
+// DefaultCompressionSkipTypes are the content types that are already compressed.
+// Types ending with slash match every subtype.
+var DefaultCompressionSkipTypes = []string{
+	ContentBinary,
+	"image/",
+	"video/",
+	"audio/",
+	"font/woff",
+	"font/woff2",
+	"application/gzip",
+	"application/x-gzip",
+	"application/zip",
+	"application/pdf",
+	ContentEventStream,
+}
+
+// Compressor compresses a response body
+type Compressor interface {
+	// Encoding returns the content coding of the compressor (e.g. gzip)
+	Encoding() string
+	// NewWriter creates a writer that compresses the data written into w
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+}
+
+// GzipCompressor compresses the response body with gzip
+type GzipCompressor struct {
+	// Level of compression. Defaults to gzip.DefaultCompression.
+	Level int
+}
+
+// Encoding returns the content coding
+func (c *GzipCompressor) Encoding() string {
+	return "gzip"
+}
+
+// NewWriter creates a new gzip writer
+func (c *GzipCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	level := c.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	return gzip.NewWriterLevel(w, level)
+}
+
+// DeflateCompressor compresses the response body with deflate
+type DeflateCompressor struct {
+	// Level of compression. Defaults to flate.DefaultCompression.
+	Level int
+}
+
+// Encoding returns the content coding
+func (c *DeflateCompressor) Encoding() string {
+	return "deflate"
+}
+
+// NewWriter creates a new deflate writer
+func (c *DeflateCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	level := c.Level
+	if level == 0 {
+		level = flate.DefaultCompression
+	}
+	return flate.NewWriter(w, level)
+}
+
+// CompressionOption configures the compression middleware
+type CompressionOption func(*compressionOptions)
+
+type compressionOptions struct {
+	minSize     int
+	compressors []Compressor
+	skipTypes   []string
+}
+
+// WithCompressionMinSize sets the minimal size of the response body that is compressed
+func WithCompressionMinSize(size int) CompressionOption {
+	return func(opts *compressionOptions) {
+		opts.minSize = size
+	}
+}
+
+// WithCompressors sets the compressors in order of preference. Defaults to gzip and deflate.
+func WithCompressors(compressors ...Compressor) CompressionOption {
+	return func(opts *compressionOptions) {
+		opts.compressors = compressors
+	}
+}
+
+// WithCompressionSkipTypes sets the content types that are not compressed
+func WithCompressionSkipTypes(types ...string) CompressionOption {
+	return func(opts *compressionOptions) {
+		opts.skipTypes = types
+	}
+}
+
+// NewHTTPCompressor compresses the responses with the compressor negotiated by the Accept-Encoding header
+func NewHTTPCompressor(options ...CompressionOption) HandlerFunc {
+	opts := &compressionOptions{
+		minSize:     DefaultCompressionMinSize,
+		compressors: []Compressor{&GzipCompressor{}, &DeflateCompressor{}},
+		skipTypes:   DefaultCompressionSkipTypes,
+	}
+
+	for _, option := range options {
+		option(opts)
+	}
+
+	return func(w http.ResponseWriter, request *http.Request, next http.HandlerFunc) {
+		addVary(w, AcceptEncoding)
+
+		compressor := negotiateCompressor(request.Header.Get(AcceptEncoding), opts.compressors)
+		if compressor == nil || request.Method == "HEAD" {
+			next(w, request)
+			return
+		}
+
+		writer := &compressWriter{
+			ResponseWriter: w,
+			compressor:     compressor,
+			options:        opts,
+		}
+		defer func() {
+			if value := recover(); value != nil {
+				// drop the buffered response so that the recovery middleware can write its own
+				writer.discard()
+				panic(value)
+			}
+		}()
+
+		next(writer.wrap(), request)
+		writer.Close()
+	}
+}
+
+type compressWriter struct {
+	http.ResponseWriter
+	compressor Compressor
+	options    *compressionOptions
+	status     int
+	buffer     []byte
+	decided    bool
+	writer     io.WriteCloser
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.decided || w.status != 0 {
+		return
+	}
+
+	if code < http.StatusOK {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	w.status = code
+	if code == http.StatusNoContent || code == http.StatusNotModified {
+		w.decide(false)
+	}
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.decided {
+		if w.writer != nil {
+			return w.writer.Write(data)
+		}
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.buffer = append(w.buffer, data...)
+	if len(w.buffer) >= w.options.minSize {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+// wrap exposes http.Flusher, http.Hijacker and http.Pusher of the wrapped writer
+func (w *compressWriter) wrap() http.ResponseWriter {
+	f, h, p := optionalFlusher{w}, optionalHijacker{w}, optionalPusher{w}
+
+	switch capabilities(w.ResponseWriter) &^ canCloseNotify {
+	case canFlush:
+		return struct {
+			*compressWriter
+			optionalFlusher
+		}{w, f}
+	case canHijack:
+		return struct {
+			*compressWriter
+			optionalHijacker
+		}{w, h}
+	case canFlush | canHijack:
+		return struct {
+			*compressWriter
+			optionalFlusher
+			optionalHijacker
+		}{w, f, h}
+	case canPush:
+		return struct {
+			*compressWriter
+			optionalPusher
+		}{w, p}
+	case canFlush | canPush:
+		return struct {
+			*compressWriter
+			optionalFlusher
+			optionalPusher
+		}{w, f, p}
+	case canHijack | canPush:
+		return struct {
+			*compressWriter
+			optionalHijacker
+			optionalPusher
+		}{w, h, p}
+	case canFlush | canHijack | canPush:
+		return struct {
+			*compressWriter
+			optionalFlusher
+			optionalHijacker
+			optionalPusher
+		}{w, f, h, p}
+	default:
+		return w
+	}
+}
+
+// Unwrap returns the wrapped http.ResponseWriter
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *compressWriter) flush() {
+	if !w.decided {
+		w.decide(true)
+	}
+
+	if flusher, ok := w.writer.(interface {
+		Flush() error
+	}); ok {
+		flusher.Flush()
+	}
+
+	w.ResponseWriter.(http.Flusher).Flush()
+}
+
+func (w *compressWriter) hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := w.ResponseWriter.(http.Hijacker).Hijack()
+	if err == nil {
+		// the connection is owned by the handler and the response must not be written
+		w.discard()
+	}
+	return conn, rw, err
+}
+
+func (w *compressWriter) closeNotify() <-chan bool {
+	return w.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
+
+func (w *compressWriter) push(target string, opts *http.PushOptions) error {
+	return w.ResponseWriter.(http.Pusher).Push(target, opts)
+}
+
+func (w *compressWriter) Close() error {
+	if !w.decided {
+		if err := w.decide(len(w.buffer) >= w.options.minSize); err != nil {
+			return err
+		}
+	}
+
+	if w.writer != nil {
+		return w.writer.Close()
+	}
+	return nil
+}
+
+// discard drops the buffered response without writing the header
+func (w *compressWriter) discard() {
+	if !w.decided {
+		w.decided = true
+		w.buffer = nil
+	}
+}
+
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+
+	header := w.Header()
+	if compress && header.Get(ContentEncoding) == "" && w.compressible() {
+		if writer, err := w.compressor.NewWriter(w.ResponseWriter); err == nil {
+			w.writer = writer
+			header.Set(ContentEncoding, w.compressor.Encoding())
+			header.Del(ContentLength)
+		}
+	}
+
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	buffer := w.buffer
+	w.buffer = nil
+	if len(buffer) == 0 {
+		return nil
+	}
+
+	var err error
+	if w.writer != nil {
+		_, err = w.writer.Write(buffer)
+	} else {
+		_, err = w.ResponseWriter.Write(buffer)
+	}
+	return err
+}
+
+func (w *compressWriter) compressible() bool {
+	contentType := w.Header().Get(ContentType)
+	if contentType == "" {
+		contentType = http.DetectContentType(w.buffer)
+		w.Header().Set(ContentType, contentType)
+	}
+
+	contentType = strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	for _, skip := range w.options.skipTypes {
+		if contentType == skip || (strings.HasSuffix(skip, "/") && strings.HasPrefix(contentType, skip)) {
+			return false
+		}
+	}
+	return true
+}
+
+func negotiateCompressor(acceptEncoding string, compressors []Compressor) Compressor {
+	qualities := map[string]float64{}
+	for _, field := range strings.Split(acceptEncoding, ",") {
+		params := strings.Split(field, ";")
+		coding := strings.ToLower(strings.TrimSpace(params[0]))
+		if coding == "" {
+			continue
+		}
+
+		qualities[coding] = quality(params[1:])
+	}
+
+	var (
+		best        Compressor
+		bestQuality float64
+	)
+
+	for _, compressor := range compressors {
+		quality, ok := qualities[compressor.Encoding()]
+		if !ok {
+			quality = qualities["*"]
+		}
+
+		if quality > bestQuality {
+			best, bestQuality = compressor, quality
+		}
+	}
+
+	return best
+}
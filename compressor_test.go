@@ -0,0 +1,229 @@
+package giraffe_test
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/svett/giraffe"
+	"github.com/svett/giraffe/fakes"
+)
+
+type identityCompressor struct{}
+
+func (identityCompressor) Encoding() string {
+	return "identity-test"
+}
+
+func (identityCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return nopWriteCloser{w}, nil
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+var _ = Describe("HTTPCompressor", func() {
+	var (
+		compressor giraffe.HandlerFunc
+		options    []giraffe.CompressionOption
+		recorder   *httptest.ResponseRecorder
+		writer     http.ResponseWriter
+		request    *http.Request
+		body       string
+		handler    http.HandlerFunc
+	)
+
+	BeforeEach(func() {
+		options = []giraffe.CompressionOption{}
+		recorder = httptest.NewRecorder()
+		writer = recorder
+		request = httptest.NewRequest("GET", "http://example.com/users", nil)
+		request.Header.Set("Accept-Encoding", "gzip, deflate")
+		body = strings.Repeat("{\"name\":\"root\"}", 100)
+
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			giraffe.NewHTTPEncoder(w).EncodeText(body)
+		}
+	})
+
+	JustBeforeEach(func() {
+		compressor = giraffe.NewHTTPCompressor(options...)
+		compressor(writer, request, handler)
+	})
+
+	It("compresses the response with gzip", func() {
+		Expect(recorder.HeaderMap).To(HaveKeyWithValue("Content-Encoding", []string{"gzip"}))
+		Expect(recorder.HeaderMap).To(HaveKeyWithValue("Content-Type", []string{"text/plain; charset=UTF-8"}))
+
+		reader, err := gzip.NewReader(recorder.Body)
+		Expect(err).NotTo(HaveOccurred())
+
+		data, err := ioutil.ReadAll(reader)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal(body))
+	})
+
+	It("varies the response by Accept-Encoding header", func() {
+		Expect(recorder.HeaderMap).To(HaveKeyWithValue("Vary", []string{"Accept-Encoding"}))
+	})
+
+	Context("when the client prefers deflate", func() {
+		BeforeEach(func() {
+			request.Header.Set("Accept-Encoding", "gzip;q=0.5, deflate")
+		})
+
+		It("compresses the response with deflate", func() {
+			Expect(recorder.HeaderMap).To(HaveKeyWithValue("Content-Encoding", []string{"deflate"}))
+
+			data, err := ioutil.ReadAll(flate.NewReader(recorder.Body))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal(body))
+		})
+	})
+
+	Context("when the client does not accept compression", func() {
+		BeforeEach(func() {
+			request.Header.Del("Accept-Encoding")
+		})
+
+		It("does not compress the response", func() {
+			Expect(recorder.HeaderMap).NotTo(HaveKey("Content-Encoding"))
+			Expect(recorder.Body.String()).To(Equal(body))
+			Expect(recorder.HeaderMap).To(HaveKeyWithValue("Vary", []string{"Accept-Encoding"}))
+		})
+	})
+
+	Context("when the response body is small", func() {
+		BeforeEach(func() {
+			body = "hello"
+		})
+
+		It("does not compress the response", func() {
+			Expect(recorder.HeaderMap).NotTo(HaveKey("Content-Encoding"))
+			Expect(recorder.Body.String()).To(Equal(body))
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+		})
+	})
+
+	Context("when the content type is already compressed", func() {
+		BeforeEach(func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				giraffe.NewHTTPEncoder(w).EncodeData([]byte(body))
+			}
+		})
+
+		It("does not compress the response", func() {
+			Expect(recorder.HeaderMap).NotTo(HaveKey("Content-Encoding"))
+			Expect(recorder.Body.String()).To(Equal(body))
+		})
+	})
+
+	Context("when the handler writes a status code", func() {
+		BeforeEach(func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(body))
+			}
+		})
+
+		It("keeps the status code", func() {
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+			Expect(recorder.HeaderMap).To(HaveKeyWithValue("Content-Encoding", []string{"gzip"}))
+		})
+	})
+
+	Context("when the content type is an event stream", func() {
+		BeforeEach(func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				stream, err := giraffe.NewHTTPEncoder(w).EncodeEventStream(r.Context())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stream.Send(&giraffe.Event{Data: body})).To(Succeed())
+			}
+		})
+
+		It("does not compress the response", func() {
+			Expect(recorder.HeaderMap).NotTo(HaveKey("Content-Encoding"))
+			Expect(recorder.Body.String()).To(ContainSubstring(body))
+		})
+	})
+
+	Context("when the handler hijacks the connection", func() {
+		var hijacker *hijackRecorder
+
+		BeforeEach(func() {
+			hijacker = &hijackRecorder{ResponseRecorder: recorder}
+			writer = hijacker
+
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("hello"))
+				_, _, err := http.NewResponseController(w).Hijack()
+				Expect(err).NotTo(HaveOccurred())
+			}
+		})
+
+		It("hijacks the wrapped writer", func() {
+			Expect(hijacker.hijacked).To(BeTrue())
+			Expect(recorder.Body.String()).To(BeEmpty())
+			Expect(recorder.HeaderMap).NotTo(HaveKey("Content-Encoding"))
+		})
+	})
+
+	Context("when the handler uses http.ResponseController", func() {
+		BeforeEach(func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				Expect(http.NewResponseController(w).Flush()).To(Succeed())
+
+				unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+				Expect(ok).To(BeTrue())
+				Expect(unwrapper.Unwrap()).To(Equal(recorder))
+			}
+		})
+
+		It("flushes and unwraps the wrapped writer", func() {
+			Expect(recorder.Flushed).To(BeTrue())
+		})
+	})
+
+	Context("when a custom compressor is used", func() {
+		BeforeEach(func() {
+			request.Header.Set("Accept-Encoding", "identity-test")
+			options = append(options, giraffe.WithCompressors(identityCompressor{}), giraffe.WithCompressionMinSize(1))
+		})
+
+		It("compresses the response with the compressor", func() {
+			Expect(recorder.HeaderMap).To(HaveKeyWithValue("Content-Encoding", []string{"identity-test"}))
+			Expect(recorder.Body.String()).To(Equal(body))
+		})
+	})
+})
+
+var _ = Describe("HTTPCompressor behind HTTPRecovery", func() {
+	It("lets the recovery write the problem details when the handler panics", func() {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest("GET", "http://example.com/users", nil)
+		request.Header.Set("Accept-Encoding", "gzip")
+
+		chain := giraffe.NewChain(giraffe.NewHTTPRecovery(new(fakes.FakeLogger)), giraffe.NewHTTPCompressor())
+		chain.ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("partial"))
+			panic("oh no!")
+		}).ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		Expect(recorder.HeaderMap).NotTo(HaveKey("Content-Encoding"))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/problem+json; charset=UTF-8"))
+		Expect(recorder.Body.String()).NotTo(ContainSubstring("partial"))
+	})
+})
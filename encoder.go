@@ -1,38 +1,164 @@
 package giraffe
 
 import (
-	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"html/template"
 	"net/http"
+	"regexp"
 )
 
+var callbackRegexp = regexp.MustCompile(`^[a-zA-Z_$][a-zA-Z0-9_$]*(?:\.[a-zA-Z_$][a-zA-Z0-9_$]*|\[[0-9]+\])*$`)
+
 // Model represents a encoder data
 type Model interface{}
 
+// CallbackError is returned when the JSONP callback is not a valid javascript identifier
+type CallbackError struct {
+	// Callback is the rejected callback
+	Callback string
+}
+
+// Error returns the error message
+func (err *CallbackError) Error() string {
+	return fmt.Sprintf("Invalid JSONP callback %q", err.Callback)
+}
+
+// XMLOption configures the XML encoding
+type XMLOption func(*xmlOptions)
+
+type xmlOptions struct {
+	prefix string
+	indent string
+	root   string
+}
+
+// WithXMLIndent indents every XML element with given prefix and indent
+func WithXMLIndent(prefix, indent string) XMLOption {
+	return func(opts *xmlOptions) {
+		opts.prefix = prefix
+		opts.indent = indent
+	}
+}
+
+// WithXMLRoot sets the name of the root XML element
+func WithXMLRoot(root string) XMLOption {
+	return func(opts *xmlOptions) {
+		opts.root = root
+	}
+}
+
+// EncoderOption configures the HTTPEncoder
+type EncoderOption func(*HTTPEncoder)
+
+// WithRequest sets the request that is served by the encoder
+func WithRequest(request *http.Request) EncoderOption {
+	return func(enc *HTTPEncoder) {
+		enc.request = request
+	}
+}
+
+// WithErrorHandler sets the handler that writes the error responses of the encoder
+func WithErrorHandler(handler ErrorHandler) EncoderOption {
+	return func(enc *HTTPEncoder) {
+		enc.errorHandler = handler
+	}
+}
+
+// WithJSONIndent indents the JSON data with given prefix and indent
+func WithJSONIndent(prefix, indent string) EncoderOption {
+	return func(enc *HTTPEncoder) {
+		enc.json.prefix = prefix
+		enc.json.indent = indent
+	}
+}
+
+// WithJSONEscapeHTML enables or disables escaping of HTML characters in JSON strings. Enabled by default.
+func WithJSONEscapeHTML(escape bool) EncoderOption {
+	return func(enc *HTTPEncoder) {
+		enc.json.escapeHTML = escape
+	}
+}
+
+// WithPrettyParam indents the JSON data when the request has given query parameter (e.g. ?pretty)
+func WithPrettyParam(param string) EncoderOption {
+	return func(enc *HTTPEncoder) {
+		enc.json.prettyParam = param
+	}
+}
+
+// WithJSONMarshaller sets the marshaller that encodes the JSON data
+func WithJSONMarshaller(marshaller JSONMarshaller) EncoderOption {
+	return func(enc *HTTPEncoder) {
+		enc.json.marshaller = marshaller
+	}
+}
+
 // HTTPEncoder encodes into a different formats
 type HTTPEncoder struct {
-	writer http.ResponseWriter
+	writer       http.ResponseWriter
+	request      *http.Request
+	errorHandler ErrorHandler
+	json         jsonOptions
 }
 
 // EncodeJSON encodes a data as json
 func (enc *HTTPEncoder) EncodeJSON(model Model) error {
 	setContentType(enc.writer, ContentJSON)
 
-	err := json.NewEncoder(enc.writer).Encode(model)
+	data, err := enc.marshalJSON(model, true)
+	if err == nil {
+		_, err = enc.writer.Write(append(data, '\n'))
+	}
 	if err != nil {
-		http.Error(enc.writer, fmt.Sprintf("Unable to encode '%v' as JSON data: %s", model, err.Error()), http.StatusInternalServerError)
+		enc.fail(http.StatusInternalServerError, "Unable to encode the model as JSON data", err)
 	}
 	return err
 }
 
-// EncodeJSONP encodes a data as jsonp
+// EncodeJSONP encodes a data as jsonp. The callback must be a javascript
+// identifier or a dotted path of identifiers, otherwise a CallbackError is returned.
 func (enc *HTTPEncoder) EncodeJSONP(callback string, model Model) error {
+	if !callbackRegexp.MatchString(callback) {
+		err := &CallbackError{Callback: callback}
+		enc.fail(http.StatusBadRequest, "The JSONP callback is not a valid javascript identifier", err)
+		return err
+	}
+
 	setContentType(enc.writer, ContentJSONP)
+	enc.writer.Header().Set("X-Content-Type-Options", "nosniff")
+
+	data, err := enc.marshalJSON(model, true)
+	if err == nil {
+		_, err = fmt.Fprintf(enc.writer, "/**/%s(%s)", callback, string(data))
+	}
+	if err != nil {
+		enc.fail(http.StatusInternalServerError, "Unable to encode the model as JSON for javascript func", err)
+	}
+	return err
+}
+
+// EncodeXML encodes a data as xml
+func (enc *HTTPEncoder) EncodeXML(model Model, options ...XMLOption) error {
+	setContentType(enc.writer, ContentXML)
+
+	opts := &xmlOptions{}
+	for _, option := range options {
+		option(opts)
+	}
+
+	encoder := xml.NewEncoder(enc.writer)
+	encoder.Indent(opts.prefix, opts.indent)
+
+	var err error
+	if opts.root != "" {
+		err = encoder.EncodeElement(model, xml.StartElement{Name: xml.Name{Local: opts.root}})
+	} else {
+		err = encoder.Encode(model)
+	}
 
-	data, _ := json.Marshal(model)
-	_, err := fmt.Fprintf(enc.writer, "%s(%s)", callback, string(data))
 	if err != nil {
-		http.Error(enc.writer, fmt.Sprintf("Unable to encode '%v' as JSON for javascript func %s: %s", model, callback, err.Error()), http.StatusInternalServerError)
+		enc.fail(http.StatusInternalServerError, "Unable to encode the model as XML data", err)
 	}
 	return err
 }
@@ -43,7 +169,7 @@ func (enc *HTTPEncoder) EncodeData(data []byte) error {
 
 	_, err := enc.writer.Write(data)
 	if err != nil {
-		http.Error(enc.writer, fmt.Sprintf("Unable to encode binary data: %s", err.Error()), http.StatusInternalServerError)
+		enc.fail(http.StatusInternalServerError, "Unable to encode binary data", err)
 	}
 	return err
 }
@@ -54,12 +180,49 @@ func (enc *HTTPEncoder) EncodeText(text string) error {
 
 	_, err := fmt.Fprint(enc.writer, text)
 	if err != nil {
-		http.Error(enc.writer, fmt.Sprintf("Unable to encode text '%s': %s", text, err.Error()), http.StatusInternalServerError)
+		enc.fail(http.StatusInternalServerError, "Unable to encode text", err)
 	}
 	return err
 }
 
+func (enc *HTTPEncoder) encodeHTML(model Model) error {
+	setContentType(enc.writer, ContentHTML)
+
+	html, ok := model.(template.HTML)
+	if !ok {
+		html = template.HTML(template.HTMLEscapeString(text(model)))
+	}
+
+	_, err := fmt.Fprint(enc.writer, html)
+	if err != nil {
+		enc.fail(http.StatusInternalServerError, "Unable to encode html", err)
+	}
+	return err
+}
+
+// EncodeProblem writes an error response for given problem
+func (enc *HTTPEncoder) EncodeProblem(problem *Problem) {
+	enc.errorHandler.HandleError(enc.writer, enc.request, problem)
+}
+
+func (enc *HTTPEncoder) fail(status int, detail string, err error) {
+	enc.EncodeProblem(newResponseProblem(enc.writer, enc.request, status, detail, err))
+}
+
 // NewHTTPEncoder creates a new encoder for concrete writer
-func NewHTTPEncoder(writer http.ResponseWriter) *HTTPEncoder {
-	return &HTTPEncoder{writer: writer}
+func NewHTTPEncoder(writer http.ResponseWriter, options ...EncoderOption) *HTTPEncoder {
+	enc := &HTTPEncoder{
+		writer:       writer,
+		errorHandler: errorHandler(),
+		json: jsonOptions{
+			marshaller: StandardJSONMarshaller,
+			escapeHTML: true,
+		},
+	}
+
+	for _, option := range options {
+		option(enc)
+	}
+
+	return enc
 }
@@ -2,6 +2,7 @@ package giraffe_test
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -29,6 +30,34 @@ var _ = Describe("HTTPEncoder", func() {
 		encoder = giraffe.NewHTTPEncoder(responseWriter)
 	})
 
+	Context("when the model cannot be encoded", func() {
+		var (
+			handler *fakes.FakeErrorHandler
+			request *http.Request
+		)
+
+		BeforeEach(func() {
+			handler = new(fakes.FakeErrorHandler)
+			request = httptest.NewRequest("GET", "http://example.com/users", nil)
+		})
+
+		JustBeforeEach(func() {
+			encoder = giraffe.NewHTTPEncoder(responseWriter, giraffe.WithRequest(request), giraffe.WithErrorHandler(handler))
+		})
+
+		It("uses the error handler of the encoder", func() {
+			Expect(encoder.EncodeJSON(map[string]interface{}{"password": "swordfish", "ch": make(chan int)})).NotTo(Succeed())
+			Expect(handler.HandleErrorCallCount()).To(Equal(1))
+
+			writer, req, problem := handler.HandleErrorArgsForCall(0)
+			Expect(writer).To(Equal(responseWriter))
+			Expect(req).To(Equal(request))
+			Expect(problem.Status).To(Equal(http.StatusInternalServerError))
+			Expect(problem.Instance).To(Equal("/users"))
+			Expect(problem.Detail).NotTo(ContainSubstring("swordfish"))
+		})
+	})
+
 	Describe("EncodeJSON", func() {
 		It("encodes a json format", func() {
 			model := map[string]string{"name": "root"}
@@ -65,7 +94,17 @@ var _ = Describe("HTTPEncoder", func() {
 		It("encodes a json format", func() {
 			model := map[string]string{"name": "Unknown"}
 			Expect(encoder.EncodeJSONP("my_callback", model)).To(Succeed())
-			Expect(recoder.Body.String()).To(Equal("my_callback({\"name\":\"Unknown\"})"))
+			Expect(recoder.Body.String()).To(Equal("/**/my_callback({\"name\":\"Unknown\"})"))
+		})
+
+		It("accepts a callback path", func() {
+			Expect(encoder.EncodeJSONP("jQuery.callbacks[12]._done", "root")).To(Succeed())
+			Expect(recoder.Body.String()).To(Equal("/**/jQuery.callbacks[12]._done(\"root\")"))
+		})
+
+		It("does not allow content sniffing", func() {
+			Expect(encoder.EncodeJSONP("my_callback", "root")).To(Succeed())
+			Expect(recoder.HeaderMap).To(HaveKeyWithValue("X-Content-Type-Options", []string{"nosniff"}))
 		})
 
 		It("has the corrent content type", func() {
@@ -88,6 +127,182 @@ var _ = Describe("HTTPEncoder", func() {
 			Expect(encoder.EncodeJSONP("my_callback_func", model)).To(Succeed())
 			Expect(recoder.Code).To(Equal(http.StatusOK))
 		})
+
+		Context("when the callback is not a javascript identifier", func() {
+			callbacks := []string{
+				"",
+				"alert(1);cb",
+				"<script>",
+				"1callback",
+				"callback.",
+				"callback[x]",
+				"callback\u2028",
+			}
+
+			It("returns a callback error", func() {
+				for _, callback := range callbacks {
+					var callbackErr *giraffe.CallbackError
+					Expect(errors.As(encoder.EncodeJSONP(callback, "root"), &callbackErr)).To(BeTrue(), callback)
+					Expect(callbackErr.Callback).To(Equal(callback))
+				}
+			})
+
+			It("has the correct status code", func() {
+				encoder.EncodeJSONP("alert(1);cb", "root")
+				Expect(recoder.Code).To(Equal(http.StatusBadRequest))
+				Expect(recoder.Body.String()).NotTo(ContainSubstring("alert(1)"))
+			})
+		})
+	})
+
+	Describe("JSON options", func() {
+		var (
+			options []giraffe.EncoderOption
+			model   map[string]string
+		)
+
+		BeforeEach(func() {
+			options = []giraffe.EncoderOption{}
+			model = map[string]string{"name": "<root>"}
+		})
+
+		JustBeforeEach(func() {
+			encoder = giraffe.NewHTTPEncoder(responseWriter, options...)
+		})
+
+		It("escapes HTML characters by default", func() {
+			Expect(encoder.EncodeJSON(model)).To(Succeed())
+			Expect(recoder.Body.String()).To(Equal("{\"name\":\"\\u003croot\\u003e\"}\n"))
+		})
+
+		Context("when HTML escaping is disabled", func() {
+			BeforeEach(func() {
+				options = append(options, giraffe.WithJSONEscapeHTML(false))
+			})
+
+			It("does not escape HTML characters", func() {
+				Expect(encoder.EncodeJSONP("callback", model)).To(Succeed())
+				Expect(recoder.Body.String()).To(ContainSubstring("{\"name\":\"<root>\"}"))
+			})
+		})
+
+		Context("when indentation is set", func() {
+			BeforeEach(func() {
+				options = append(options, giraffe.WithJSONEscapeHTML(false), giraffe.WithJSONIndent("", "\t"))
+			})
+
+			It("indents the JSON data", func() {
+				Expect(encoder.EncodeJSON(model)).To(Succeed())
+				Expect(recoder.Body.String()).To(Equal("{\n\t\"name\": \"<root>\"\n}\n"))
+			})
+		})
+
+		Context("when the request has pretty query parameter", func() {
+			BeforeEach(func() {
+				request := httptest.NewRequest("GET", "http://example.com/users?pretty", nil)
+				options = append(options, giraffe.WithJSONEscapeHTML(false), giraffe.WithRequest(request), giraffe.WithPrettyParam("pretty"))
+			})
+
+			It("indents the JSON data", func() {
+				Expect(encoder.EncodeJSON(model)).To(Succeed())
+				Expect(recoder.Body.String()).To(Equal("{\n  \"name\": \"<root>\"\n}\n"))
+			})
+		})
+
+		Context("when the request does not have pretty query parameter", func() {
+			BeforeEach(func() {
+				request := httptest.NewRequest("GET", "http://example.com/users?pretty=false", nil)
+				options = append(options, giraffe.WithJSONEscapeHTML(false), giraffe.WithRequest(request), giraffe.WithPrettyParam("pretty"))
+			})
+
+			It("does not indent the JSON data", func() {
+				Expect(encoder.EncodeJSON(model)).To(Succeed())
+				Expect(recoder.Body.String()).To(Equal("{\"name\":\"<root>\"}\n"))
+			})
+		})
+
+		Context("when a marshaller is set", func() {
+			BeforeEach(func() {
+				options = append(options, giraffe.WithJSONMarshaller(giraffe.JSONMarshallerFunc(func(model interface{}) ([]byte, error) {
+					return []byte(`{"marshaller":"custom"}`), nil
+				})))
+			})
+
+			It("uses the marshaller", func() {
+				Expect(encoder.EncodeJSON(model)).To(Succeed())
+				Expect(recoder.Body.String()).To(Equal("{\"marshaller\":\"custom\"}\n"))
+			})
+		})
+
+		Context("when the marshaller is a Marshal func", func() {
+			BeforeEach(func() {
+				options = append(options, giraffe.WithJSONMarshaller(giraffe.JSONMarshallerFunc(json.Marshal)))
+			})
+
+			It("uses the marshaller", func() {
+				Expect(encoder.EncodeJSON(model)).To(Succeed())
+				Expect(recoder.Body.String()).To(Equal("{\"name\":\"\\u003croot\\u003e\"}\n"))
+			})
+		})
+
+		Context("when the marshaller fails", func() {
+			BeforeEach(func() {
+				options = append(options, giraffe.WithJSONMarshaller(giraffe.JSONMarshallerFunc(func(model interface{}) ([]byte, error) {
+					return nil, fmt.Errorf("Oh no!")
+				})))
+			})
+
+			It("returns the error", func() {
+				Expect(encoder.EncodeJSONP("callback", model)).To(MatchError("Oh no!"))
+				Expect(recoder.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+	})
+
+	Describe("EncodeXML", func() {
+		type user struct {
+			Name string `xml:"name"`
+		}
+
+		It("encodes a xml format", func() {
+			Expect(encoder.EncodeXML(&user{Name: "root"})).To(Succeed())
+			Expect(recoder.Body.String()).To(Equal("<user><name>root</name></user>"))
+		})
+
+		It("encodes a xml format with indentation", func() {
+			Expect(encoder.EncodeXML(&user{Name: "root"}, giraffe.WithXMLIndent("", "  "))).To(Succeed())
+			Expect(recoder.Body.String()).To(Equal("<user>\n  <name>root</name>\n</user>"))
+		})
+
+		It("encodes a xml format with custom root element", func() {
+			Expect(encoder.EncodeXML(&user{Name: "root"}, giraffe.WithXMLRoot("account"))).To(Succeed())
+			Expect(recoder.Body.String()).To(Equal("<account><name>root</name></account>"))
+		})
+
+		It("has the corrent content type", func() {
+			Expect(encoder.EncodeXML(&user{Name: "root"})).To(Succeed())
+			Expect(recoder.HeaderMap).To(HaveKeyWithValue("Content-Type", []string{"application/xml; charset=UTF-8"}))
+		})
+
+		Context("when the content type is already set", func() {
+			It("does not change it", func() {
+				recoder.Header().Set("Content-Type", "Unknown")
+				Expect(encoder.EncodeXML(&user{Name: "root"})).To(Succeed())
+				Expect(recoder.HeaderMap).To(HaveKeyWithValue("Content-Type", []string{"Unknown"}))
+			})
+		})
+
+		It("has the correct status code", func() {
+			Expect(encoder.EncodeXML(&user{Name: "root"})).To(Succeed())
+			Expect(recoder.Code).To(Equal(http.StatusOK))
+		})
+
+		Context("when the model cannot be encoded", func() {
+			It("has correct status code", func() {
+				Expect(encoder.EncodeXML(map[string]string{"name": "root"})).NotTo(Succeed())
+				Expect(recoder.Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
 	})
 
 	Describe("EncodeData", func() {
@@ -150,6 +365,11 @@ var _ = Describe("HTTPEncoder", func() {
 			responseWriter = fakeResponseWriter
 		})
 
+		It("writes problem details", func() {
+			encoder.EncodeText("hello")
+			Expect(fakeResponseWriter.Header()).To(HaveKeyWithValue("Content-Type", []string{"application/problem+json; charset=UTF-8"}))
+		})
+
 		Describe("EncodeJSON", func() {
 			It("returns the error", func() {
 				model := map[string]string{"name": "root"}
@@ -176,6 +396,17 @@ var _ = Describe("HTTPEncoder", func() {
 			})
 		})
 
+		Describe("EncodeXML", func() {
+			It("returns the error", func() {
+				Expect(encoder.EncodeXML("root")).To(MatchError("Oh no!"))
+			})
+
+			It("has correct status code", func() {
+				encoder.EncodeXML("root")
+				Expect(fakeResponseWriter.Code()).To(Equal(http.StatusInternalServerError))
+			})
+		})
+
 		Describe("EncodeData", func() {
 			It("returns the error", func() {
 				Expect(encoder.EncodeData([]byte("hello"))).To(MatchError("Oh no!"))
@@ -0,0 +1,46 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/svett/giraffe"
+)
+
+type FakeErrorHandler struct {
+	HandleErrorStub        func(writer http.ResponseWriter, request *http.Request, problem *giraffe.Problem)
+	handleErrorMutex       sync.RWMutex
+	handleErrorArgsForCall []struct {
+		writer  http.ResponseWriter
+		request *http.Request
+		problem *giraffe.Problem
+	}
+}
+
+func (fake *FakeErrorHandler) HandleError(writer http.ResponseWriter, request *http.Request, problem *giraffe.Problem) {
+	fake.handleErrorMutex.Lock()
+	fake.handleErrorArgsForCall = append(fake.handleErrorArgsForCall, struct {
+		writer  http.ResponseWriter
+		request *http.Request
+		problem *giraffe.Problem
+	}{writer, request, problem})
+	fake.handleErrorMutex.Unlock()
+	if fake.HandleErrorStub != nil {
+		fake.HandleErrorStub(writer, request, problem)
+	}
+}
+
+func (fake *FakeErrorHandler) HandleErrorCallCount() int {
+	fake.handleErrorMutex.RLock()
+	defer fake.handleErrorMutex.RUnlock()
+	return len(fake.handleErrorArgsForCall)
+}
+
+func (fake *FakeErrorHandler) HandleErrorArgsForCall(i int) (http.ResponseWriter, *http.Request, *giraffe.Problem) {
+	fake.handleErrorMutex.RLock()
+	defer fake.handleErrorMutex.RUnlock()
+	return fake.handleErrorArgsForCall[i].writer, fake.handleErrorArgsForCall[i].request, fake.handleErrorArgsForCall[i].problem
+}
+
+var _ giraffe.ErrorHandler = new(FakeErrorHandler)
@@ -0,0 +1,133 @@
+// This file was generated by counterfeiter
+package fakes
+
+import (
+	"sync"
+
+	"github.com/svett/giraffe"
+)
+
+type FakeLeveledLogger struct {
+	DebugStub        func(msg string, keyvals ...interface{})
+	debugMutex       sync.RWMutex
+	debugArgsForCall []struct {
+		msg     string
+		keyvals []interface{}
+	}
+	InfoStub        func(msg string, keyvals ...interface{})
+	infoMutex       sync.RWMutex
+	infoArgsForCall []struct {
+		msg     string
+		keyvals []interface{}
+	}
+	WarnStub        func(msg string, keyvals ...interface{})
+	warnMutex       sync.RWMutex
+	warnArgsForCall []struct {
+		msg     string
+		keyvals []interface{}
+	}
+	ErrorStub        func(msg string, keyvals ...interface{})
+	errorMutex       sync.RWMutex
+	errorArgsForCall []struct {
+		msg     string
+		keyvals []interface{}
+	}
+}
+
+func (fake *FakeLeveledLogger) Debug(msg string, keyvals ...interface{}) {
+	fake.debugMutex.Lock()
+	fake.debugArgsForCall = append(fake.debugArgsForCall, struct {
+		msg     string
+		keyvals []interface{}
+	}{msg, keyvals})
+	fake.debugMutex.Unlock()
+	if fake.DebugStub != nil {
+		fake.DebugStub(msg, keyvals...)
+	}
+}
+
+func (fake *FakeLeveledLogger) DebugCallCount() int {
+	fake.debugMutex.RLock()
+	defer fake.debugMutex.RUnlock()
+	return len(fake.debugArgsForCall)
+}
+
+func (fake *FakeLeveledLogger) DebugArgsForCall(i int) (string, []interface{}) {
+	fake.debugMutex.RLock()
+	defer fake.debugMutex.RUnlock()
+	return fake.debugArgsForCall[i].msg, fake.debugArgsForCall[i].keyvals
+}
+
+func (fake *FakeLeveledLogger) Info(msg string, keyvals ...interface{}) {
+	fake.infoMutex.Lock()
+	fake.infoArgsForCall = append(fake.infoArgsForCall, struct {
+		msg     string
+		keyvals []interface{}
+	}{msg, keyvals})
+	fake.infoMutex.Unlock()
+	if fake.InfoStub != nil {
+		fake.InfoStub(msg, keyvals...)
+	}
+}
+
+func (fake *FakeLeveledLogger) InfoCallCount() int {
+	fake.infoMutex.RLock()
+	defer fake.infoMutex.RUnlock()
+	return len(fake.infoArgsForCall)
+}
+
+func (fake *FakeLeveledLogger) InfoArgsForCall(i int) (string, []interface{}) {
+	fake.infoMutex.RLock()
+	defer fake.infoMutex.RUnlock()
+	return fake.infoArgsForCall[i].msg, fake.infoArgsForCall[i].keyvals
+}
+
+func (fake *FakeLeveledLogger) Warn(msg string, keyvals ...interface{}) {
+	fake.warnMutex.Lock()
+	fake.warnArgsForCall = append(fake.warnArgsForCall, struct {
+		msg     string
+		keyvals []interface{}
+	}{msg, keyvals})
+	fake.warnMutex.Unlock()
+	if fake.WarnStub != nil {
+		fake.WarnStub(msg, keyvals...)
+	}
+}
+
+func (fake *FakeLeveledLogger) WarnCallCount() int {
+	fake.warnMutex.RLock()
+	defer fake.warnMutex.RUnlock()
+	return len(fake.warnArgsForCall)
+}
+
+func (fake *FakeLeveledLogger) WarnArgsForCall(i int) (string, []interface{}) {
+	fake.warnMutex.RLock()
+	defer fake.warnMutex.RUnlock()
+	return fake.warnArgsForCall[i].msg, fake.warnArgsForCall[i].keyvals
+}
+
+func (fake *FakeLeveledLogger) Error(msg string, keyvals ...interface{}) {
+	fake.errorMutex.Lock()
+	fake.errorArgsForCall = append(fake.errorArgsForCall, struct {
+		msg     string
+		keyvals []interface{}
+	}{msg, keyvals})
+	fake.errorMutex.Unlock()
+	if fake.ErrorStub != nil {
+		fake.ErrorStub(msg, keyvals...)
+	}
+}
+
+func (fake *FakeLeveledLogger) ErrorCallCount() int {
+	fake.errorMutex.RLock()
+	defer fake.errorMutex.RUnlock()
+	return len(fake.errorArgsForCall)
+}
+
+func (fake *FakeLeveledLogger) ErrorArgsForCall(i int) (string, []interface{}) {
+	fake.errorMutex.RLock()
+	defer fake.errorMutex.RUnlock()
+	return fake.errorArgsForCall[i].msg, fake.errorArgsForCall[i].keyvals
+}
+
+var _ giraffe.LeveledLogger = new(FakeLeveledLogger)
@@ -0,0 +1,92 @@
+package giraffe
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// JSONMarshaller encodes a model as compact JSON without escaping HTML characters
+type JSONMarshaller interface {
+	Marshal(model Model) ([]byte, error)
+}
+
+// JSONMarshallerFunc converts a func into JSONMarshaller. It accepts the
+// Marshal func of encoding/json compatible packages, e.g. jsoniter.Marshal.
+type JSONMarshallerFunc func(v interface{}) ([]byte, error)
+
+// Marshal encodes a model as JSON
+func (f JSONMarshallerFunc) Marshal(model Model) ([]byte, error) {
+	return f(model)
+}
+
+// StandardJSONMarshaller encodes a model by using encoding/json package
+var StandardJSONMarshaller JSONMarshaller = JSONMarshallerFunc(func(model interface{}) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(model); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), nil
+})
+
+type jsonOptions struct {
+	marshaller  JSONMarshaller
+	prefix      string
+	indent      string
+	escapeHTML  bool
+	prettyParam string
+}
+
+func (enc *HTTPEncoder) marshalJSON(model Model, indent bool) ([]byte, error) {
+	data, err := enc.json.marshaller.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+
+	if enc.json.escapeHTML {
+		buffer := &bytes.Buffer{}
+		json.HTMLEscape(buffer, data)
+		data = buffer.Bytes()
+	}
+
+	if !indent {
+		return data, nil
+	}
+
+	prefix, indentation := enc.json.prefix, enc.json.indent
+	if prefix == "" && indentation == "" && enc.pretty() {
+		indentation = "  "
+	}
+
+	if prefix == "" && indentation == "" {
+		return data, nil
+	}
+
+	buffer := &bytes.Buffer{}
+	if err = json.Indent(buffer, data, prefix, indentation); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func (enc *HTTPEncoder) pretty() bool {
+	if enc.json.prettyParam == "" || enc.request == nil || enc.request.URL == nil {
+		return false
+	}
+
+	query := enc.request.URL.Query()
+	if _, ok := query[enc.json.prettyParam]; !ok {
+		return false
+	}
+
+	switch query.Get(enc.json.prettyParam) {
+	case "0", "false":
+		return false
+	default:
+		return true
+	}
+}
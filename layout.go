@@ -0,0 +1,60 @@
+package giraffe
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+)
+
+// ErrLayoutNotYield is returned when the layout does not call yield
+var ErrLayoutNotYield = errors.New("The layout does not yield the content")
+
+// yieldMarker is written by yield and replaced with the rendered content of the page.
+// It is alphanumeric so that it is not changed by the escaping of any context, e.g. <title>.
+var yieldMarker = []byte("giraffeyield" + NewRequestID())
+
+// LayoutFuncs returns the functions of the layouts. The yield function
+// renders the content of the page. It can be called more than once. The functions are added to the templates
+// of HTMLTemplateRepository. A custom HTMLTemplateProvider should add them
+// before the templates are parsed.
+func LayoutFuncs() template.FuncMap {
+	return template.FuncMap{
+		"yield": func() template.HTML {
+			return template.HTML(yieldMarker)
+		},
+	}
+}
+
+// executeLayout renders the page wrapped with the layout into the buffer
+func executeLayout(buffer *bytes.Buffer, templates *template.Template, layout, page string, model Model) error {
+	content := getBuffer()
+	defer putBuffer(content)
+
+	if err := templates.ExecuteTemplate(content, page, model); err != nil {
+		return err
+	}
+
+	wrapper := getBuffer()
+	defer putBuffer(wrapper)
+
+	if err := templates.ExecuteTemplate(wrapper, layout, model); err != nil {
+		return err
+	}
+
+	data := wrapper.Bytes()
+	if !bytes.Contains(data, yieldMarker) {
+		return ErrLayoutNotYield
+	}
+
+	for {
+		index := bytes.Index(data, yieldMarker)
+		if index == -1 {
+			buffer.Write(data)
+			return nil
+		}
+
+		buffer.Write(data[:index])
+		buffer.Write(content.Bytes())
+		data = data[index+len(yieldMarker):]
+	}
+}
@@ -0,0 +1,137 @@
+package giraffe
+
+import (
+	"context"
+	"log"
+	"log/slog"
+)
+
+// Level is a severity of a log message
+type Level int8
+
+const (
+	// LevelDebug is a level of debug messages
+	LevelDebug Level = iota
+	// LevelInfo is a level of info messages
+	LevelInfo
+	// LevelWarn is a level of warning messages
+	LevelWarn
+	// LevelError is a level of error messages
+	LevelError
+)
+
+// String returns the name of the level
+func (level Level) String() string {
+	switch level {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+//go:generate counterfeiter -o fakes/fake_leveled_logger.go . LeveledLogger
+
+// LeveledLogger logs messages with severity level and alternating key/value fields
+type LeveledLogger interface {
+	// Debug writes a debug message
+	Debug(msg string, keyvals ...interface{})
+	// Info writes an info message
+	Info(msg string, keyvals ...interface{})
+	// Warn writes a warning message
+	Warn(msg string, keyvals ...interface{})
+	// Error writes an error message
+	Error(msg string, keyvals ...interface{})
+}
+
+// NewStdLeveledLogger creates a LeveledLogger that writes logfmt lines into the standard library logger
+func NewStdLeveledLogger(logger *log.Logger) LeveledLogger {
+	return &stdLeveledLogger{logger: logger}
+}
+
+type stdLeveledLogger struct {
+	logger *log.Logger
+}
+
+func (logger *stdLeveledLogger) Debug(msg string, keyvals ...interface{}) {
+	logger.log(LevelDebug, msg, keyvals)
+}
+
+func (logger *stdLeveledLogger) Info(msg string, keyvals ...interface{}) {
+	logger.log(LevelInfo, msg, keyvals)
+}
+
+func (logger *stdLeveledLogger) Warn(msg string, keyvals ...interface{}) {
+	logger.log(LevelWarn, msg, keyvals)
+}
+
+func (logger *stdLeveledLogger) Error(msg string, keyvals ...interface{}) {
+	logger.log(LevelError, msg, keyvals)
+}
+
+func (logger *stdLeveledLogger) log(level Level, msg string, keyvals []interface{}) {
+	line := logfmt(append([]interface{}{"level", level.String(), "msg", msg}, keyvals...)...)
+	logger.logger.Println(line)
+}
+
+// NewSlogLeveledLogger creates a LeveledLogger that writes into log/slog logger
+func NewSlogLeveledLogger(logger *slog.Logger) LeveledLogger {
+	return &slogLeveledLogger{logger: logger}
+}
+
+type slogLeveledLogger struct {
+	logger *slog.Logger
+}
+
+func (logger *slogLeveledLogger) Debug(msg string, keyvals ...interface{}) {
+	logger.logger.Log(context.Background(), slog.LevelDebug, msg, keyvals...)
+}
+
+func (logger *slogLeveledLogger) Info(msg string, keyvals ...interface{}) {
+	logger.logger.Log(context.Background(), slog.LevelInfo, msg, keyvals...)
+}
+
+func (logger *slogLeveledLogger) Warn(msg string, keyvals ...interface{}) {
+	logger.logger.Log(context.Background(), slog.LevelWarn, msg, keyvals...)
+}
+
+func (logger *slogLeveledLogger) Error(msg string, keyvals ...interface{}) {
+	logger.logger.Log(context.Background(), slog.LevelError, msg, keyvals...)
+}
+
+// NopLogger discards all messages. It implements both Logger and LeveledLogger.
+type NopLogger struct{}
+
+// Println discards the message
+func (NopLogger) Println(...interface{}) {}
+
+// Debug discards the message
+func (NopLogger) Debug(string, ...interface{}) {}
+
+// Info discards the message
+func (NopLogger) Info(string, ...interface{}) {}
+
+// Warn discards the message
+func (NopLogger) Warn(string, ...interface{}) {}
+
+// Error discards the message
+func (NopLogger) Error(string, ...interface{}) {}
+
+func logAt(logger LeveledLogger, level Level, msg string, keyvals ...interface{}) {
+	switch level {
+	case LevelDebug:
+		logger.Debug(msg, keyvals...)
+	case LevelInfo:
+		logger.Info(msg, keyvals...)
+	case LevelWarn:
+		logger.Warn(msg, keyvals...)
+	default:
+		logger.Error(msg, keyvals...)
+	}
+}
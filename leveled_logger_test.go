@@ -0,0 +1,72 @@
+package giraffe_test
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/svett/giraffe"
+)
+
+var _ = Describe("LeveledLogger", func() {
+	var buffer *bytes.Buffer
+
+	BeforeEach(func() {
+		buffer = &bytes.Buffer{}
+	})
+
+	Describe("NewStdLeveledLogger", func() {
+		It("writes logfmt lines with the level", func() {
+			logger := giraffe.NewStdLeveledLogger(log.New(buffer, "", 0))
+			logger.Warn("slow request", "path", "/users", "latency_ms", 1.5)
+			logger.Error("failed request", "error", "oh no!")
+
+			Expect(buffer.String()).To(Equal(
+				"level=WARN msg=\"slow request\" path=/users latency_ms=1.5\n" +
+					"level=ERROR msg=\"failed request\" error=\"oh no!\"\n",
+			))
+		})
+	})
+
+	Describe("NewSlogLeveledLogger", func() {
+		It("writes the records into slog logger", func() {
+			handler := slog.NewTextHandler(buffer, &slog.HandlerOptions{
+				Level: slog.LevelDebug,
+				ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+					if attr.Key == slog.TimeKey {
+						return slog.Attr{}
+					}
+					return attr
+				},
+			})
+
+			logger := giraffe.NewSlogLeveledLogger(slog.New(handler))
+			logger.Debug("debug", "status", 200)
+			logger.Info("info")
+
+			Expect(buffer.String()).To(Equal("level=DEBUG msg=debug status=200\nlevel=INFO msg=info\n"))
+		})
+	})
+
+	Describe("NopLogger", func() {
+		It("implements both logger interfaces", func() {
+			var logger giraffe.LeveledLogger = giraffe.NopLogger{}
+			logger.Info("info")
+
+			var printer giraffe.Logger = giraffe.NopLogger{}
+			printer.Println("info")
+		})
+	})
+
+	Describe("Level", func() {
+		It("has a name", func() {
+			Expect(giraffe.LevelDebug.String()).To(Equal("DEBUG"))
+			Expect(giraffe.LevelInfo.String()).To(Equal("INFO"))
+			Expect(giraffe.LevelWarn.String()).To(Equal("WARN"))
+			Expect(giraffe.LevelError.String()).To(Equal("ERROR"))
+		})
+	})
+})
@@ -1,10 +1,12 @@
 package giraffe
 
 import (
-	"fmt"
 	"log"
+	"math/rand"
 	"net/http"
 	"os"
+	"regexp"
+	"strings"
 	"time"
 )
 
@@ -47,62 +49,195 @@ func NewHTTPStandardLogger() HandlerFunc {
 	return NewHTTPLogger(log.New(os.Stdout, "HTTP ", log.LstdFlags), color)
 }
 
+// LoggerOption configures the HTTP logger
+type LoggerOption func(*httpLogger)
+
+// WithFormatter sets the formatter of the access log entries
+func WithFormatter(formatter AccessLogFormatter) LoggerOption {
+	return func(logger *httpLogger) {
+		logger.formatter = formatter
+	}
+}
+
+// WithIPResolver sets the resolver of the client IP. By default the client IP
+// stored by the NewHTTPClientIP middleware or the remote address without port is logged.
+func WithIPResolver(resolver *IPResolver) LoggerOption {
+	return func(logger *httpLogger) {
+		logger.resolver = resolver
+	}
+}
+
+// WithLevelThresholds sets the lowest status codes that are logged at warning
+// and error level by the leveled logger. Defaults to 400 and 500.
+func WithLevelThresholds(warnStatus, errorStatus int) LoggerOption {
+	return func(logger *httpLogger) {
+		logger.warnStatus = warnStatus
+		logger.errorStatus = errorStatus
+	}
+}
+
+// WithSkipPaths skips logging of the requests with given paths (e.g. /healthz)
+func WithSkipPaths(paths ...string) LoggerOption {
+	return func(logger *httpLogger) {
+		for _, path := range paths {
+			logger.skipPaths[path] = struct{}{}
+		}
+	}
+}
+
+// WithSkipPrefixes skips logging of the requests which paths start with any of given prefixes (e.g. /assets/)
+func WithSkipPrefixes(prefixes ...string) LoggerOption {
+	return func(logger *httpLogger) {
+		logger.skipPrefixes = append(logger.skipPrefixes, prefixes...)
+	}
+}
+
+// WithSkipPattern skips logging of the requests which paths match given regular expression
+func WithSkipPattern(pattern *regexp.Regexp) LoggerOption {
+	return func(logger *httpLogger) {
+		logger.skipPatterns = append(logger.skipPatterns, pattern)
+	}
+}
+
+// WithSampleRate logs only given fraction (between 0 and 1) of the successful requests.
+// The failed and slow requests are always logged. Defaults to 1.
+func WithSampleRate(rate float64) LoggerOption {
+	return func(logger *httpLogger) {
+		logger.sampleRate = rate
+	}
+}
+
+// WithSlowThreshold always logs the requests which latency exceeds given threshold
+// regardless of the sample rate
+func WithSlowThreshold(threshold time.Duration) LoggerOption {
+	return func(logger *httpLogger) {
+		logger.slowThreshold = threshold
+	}
+}
+
 // NewHTTPLogger logs a HTTP requests
-func NewHTTPLogger(logger Logger, color bool) HandlerFunc {
-	return func(w http.ResponseWriter, request *http.Request, next http.HandlerFunc) {
-		// Start timer
-		start := time.Now()
-		path := request.URL.Path
-
-		// Process request
-		writer := &responseWriter{ResponseWriter: w}
-		next(writer, request)
-
-		// Stop timer
-		end := time.Now()
-		latency := end.Sub(start)
-
-		clientIP := request.RemoteAddr
-		method := request.Method
-		statusCode := writer.Status()
-
-		var (
-			statusColor string
-			methodColor string
-			resetColor  string
-		)
-
-		if color {
-			statusColor = colorForStatus(statusCode)
-			methodColor = colorForMethod(method)
-			resetColor = DefaultColor
+func NewHTTPLogger(logger Logger, color bool, options ...LoggerOption) HandlerFunc {
+	return newHTTPLogger(logger, nil, color, options).handle
+}
+
+// NewHTTPLeveledLogger logs a HTTP requests with structured fields. The successful
+// requests are logged at info level, client errors at warning level and server errors
+// at error level.
+func NewHTTPLeveledLogger(logger LeveledLogger, options ...LoggerOption) HandlerFunc {
+	return newHTTPLogger(nil, logger, false, options).handle
+}
+
+func newHTTPLogger(logger Logger, leveled LeveledLogger, color bool, options []LoggerOption) *httpLogger {
+	httpLogger := &httpLogger{
+		logger:      logger,
+		leveled:     leveled,
+		formatter:   &TextFormatter{Color: color},
+		warnStatus:  http.StatusBadRequest,
+		errorStatus: http.StatusInternalServerError,
+		skipPaths:   map[string]struct{}{},
+		sampleRate:  1,
+	}
+
+	for _, option := range options {
+		option(httpLogger)
+	}
+
+	return httpLogger
+}
+
+type httpLogger struct {
+	logger        Logger
+	leveled       LeveledLogger
+	formatter     AccessLogFormatter
+	warnStatus    int
+	errorStatus   int
+	skipPaths     map[string]struct{}
+	skipPrefixes  []string
+	skipPatterns  []*regexp.Regexp
+	sampleRate    float64
+	slowThreshold time.Duration
+	resolver      *IPResolver
+}
+
+func (logger *httpLogger) handle(w http.ResponseWriter, request *http.Request, next http.HandlerFunc) {
+	if logger.skip(request.URL.Path) {
+		next(w, request)
+		return
+	}
+
+	// Process request
+	writer := NewResponseWriter(w)
+	defer func() {
+		if value := recover(); value != nil {
+			// log the request that has not been served and propagate the panic
+			logger.log(request, writer, http.StatusInternalServerError)
+			panic(value)
 		}
+	}()
 
-		msg := fmt.Sprintf("%s %3d %s| %13v | %s |%s  %s %-7s %s",
-			statusColor, statusCode, resetColor,
-			latency,
-			clientIP,
-			methodColor, resetColor, method,
-			path,
-		)
+	next(writer, request)
+	logger.log(request, writer, writer.Status())
+}
 
-		logger.Println(msg)
+func (logger *httpLogger) log(request *http.Request, writer ResponseWriter, status int) {
+	entry := newAccessLogEntry(request, writer)
+	entry.Status = status
+	if logger.resolver != nil {
+		entry.RemoteIP = logger.resolver.Resolve(request)
+	}
+	if !logger.sample(entry) {
+		return
 	}
+
+	if logger.leveled != nil {
+		logAt(logger.leveled, logger.level(entry.Status), "HTTP request", entry.Fields()...)
+		return
+	}
+
+	logger.logger.Println(logger.formatter.Format(entry))
 }
 
-// HTTPResponseWriter writes an response
-type responseWriter struct {
-	http.ResponseWriter
-	status int
+func (logger *httpLogger) skip(path string) bool {
+	if _, ok := logger.skipPaths[path]; ok {
+		return true
+	}
+
+	for _, prefix := range logger.skipPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+
+	for _, pattern := range logger.skipPatterns {
+		if pattern.MatchString(path) {
+			return true
+		}
+	}
+
+	return false
 }
 
-func (w *responseWriter) Status() int {
-	return w.status
+func (logger *httpLogger) sample(entry *AccessLogEntry) bool {
+	if logger.sampleRate >= 1 || entry.Status >= logger.warnStatus {
+		return true
+	}
+
+	if logger.slowThreshold > 0 && entry.Latency >= logger.slowThreshold {
+		return true
+	}
+
+	return rand.Float64() < logger.sampleRate
 }
 
-func (w *responseWriter) WriteHeader(code int) {
-	w.status = code
-	w.ResponseWriter.WriteHeader(code)
+func (logger *httpLogger) level(status int) Level {
+	switch {
+	case status >= logger.errorStatus:
+		return LevelError
+	case status >= logger.warnStatus:
+		return LevelWarn
+	default:
+		return LevelInfo
+	}
 }
 
 func colorForStatus(code int) string {
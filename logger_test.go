@@ -1,8 +1,11 @@
 package giraffe_test
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -55,4 +58,177 @@ var _ = Describe("HTTPLogger", func() {
 		Expect(msg).To(ContainSubstring("GET"))
 		Expect(msg).To(ContainSubstring("/foo"))
 	})
+
+	It("logs the implicit status code", func() {
+		logHandler = giraffe.NewHTTPLogger(logger, false, giraffe.WithFormatter(giraffe.NewPatternFormatter("%s %b")))
+		logHandler(writer, request, func(w http.ResponseWriter, req *http.Request) {
+			giraffe.NewHTTPEncoder(w).EncodeJSON("root")
+		})
+
+		Expect(logger.PrintlnArgsForCall(0)[0]).To(Equal("200 7"))
+	})
+
+	It("preserves the optional interfaces of the writer", func() {
+		logHandler(writer, request, func(w http.ResponseWriter, req *http.Request) {
+			_, ok := w.(http.Flusher)
+			Expect(ok).To(BeTrue())
+
+			_, ok = w.(giraffe.ResponseWriter)
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	It("logs the request when the next handler panics", func() {
+		logHandler = giraffe.NewHTTPLogger(logger, false, giraffe.WithFormatter(giraffe.NewPatternFormatter("%s %U")))
+
+		Expect(func() {
+			logHandler(writer, request, func(w http.ResponseWriter, req *http.Request) {
+				panic("oh no!")
+			})
+		}).To(PanicWith("oh no!"))
+
+		Expect(logger.PrintlnArgsForCall(0)[0]).To(Equal("500 /foo"))
+	})
+
+	Context("when the logger has a formatter", func() {
+		BeforeEach(func() {
+			logHandler = giraffe.NewHTTPLogger(logger, false, giraffe.WithFormatter(&giraffe.JSONFormatter{}))
+			request.Header.Set("User-Agent", "giraffe")
+			request.Header.Set("X-Request-ID", "42")
+		})
+
+		It("writes the structured entry", func() {
+			logHandler(writer, request, func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("hello"))
+			})
+
+			Expect(logger.PrintlnCallCount()).To(Equal(1))
+
+			var entry map[string]interface{}
+			Expect(json.Unmarshal([]byte(logger.PrintlnArgsForCall(0)[0].(string)), &entry)).To(Succeed())
+			Expect(entry).To(HaveKeyWithValue("method", "GET"))
+			Expect(entry).To(HaveKeyWithValue("path", "/foo"))
+			Expect(entry).To(HaveKeyWithValue("status", BeNumerically("==", 200)))
+			Expect(entry).To(HaveKeyWithValue("bytes", BeNumerically("==", 5)))
+			Expect(entry).To(HaveKeyWithValue("user_agent", "giraffe"))
+			Expect(entry).To(HaveKeyWithValue("request_id", "42"))
+		})
+	})
+})
+
+var _ = Describe("HTTPLeveledLogger", func() {
+	var (
+		logHandler giraffe.HandlerFunc
+		logger     *fakes.FakeLeveledLogger
+		options    []giraffe.LoggerOption
+		request    *http.Request
+	)
+
+	BeforeEach(func() {
+		logger = new(fakes.FakeLeveledLogger)
+		options = []giraffe.LoggerOption{}
+		request = httptest.NewRequest("GET", "http://example.com/foo", nil)
+	})
+
+	JustBeforeEach(func() {
+		logHandler = giraffe.NewHTTPLeveledLogger(logger, options...)
+	})
+
+	serve := func(status int) {
+		logHandler(httptest.NewRecorder(), request, func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(status)
+		})
+	}
+
+	It("logs successful requests at info level", func() {
+		serve(http.StatusOK)
+		Expect(logger.InfoCallCount()).To(Equal(1))
+
+		msg, fields := logger.InfoArgsForCall(0)
+		Expect(msg).To(Equal("HTTP request"))
+		Expect(fields).To(ContainElement("/foo"))
+		Expect(fields).To(ContainElement(http.StatusOK))
+	})
+
+	It("logs client errors at warning level", func() {
+		serve(http.StatusNotFound)
+		Expect(logger.WarnCallCount()).To(Equal(1))
+		Expect(logger.InfoCallCount()).To(Equal(0))
+	})
+
+	It("logs server errors at error level", func() {
+		serve(http.StatusBadGateway)
+		Expect(logger.ErrorCallCount()).To(Equal(1))
+	})
+
+	Context("when the paths are skipped", func() {
+		BeforeEach(func() {
+			options = append(options,
+				giraffe.WithSkipPaths("/healthz"),
+				giraffe.WithSkipPrefixes("/assets/"),
+				giraffe.WithSkipPattern(regexp.MustCompile(`^/metrics`)),
+			)
+		})
+
+		It("does not log the skipped requests", func() {
+			for _, path := range []string{"/healthz", "/assets/app.js", "/metrics/cpu"} {
+				request = httptest.NewRequest("GET", "http://example.com"+path, nil)
+				serve(http.StatusInternalServerError)
+			}
+
+			Expect(logger.ErrorCallCount()).To(Equal(0))
+		})
+
+		It("logs the other requests", func() {
+			serve(http.StatusOK)
+			Expect(logger.InfoCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when the requests are sampled", func() {
+		BeforeEach(func() {
+			options = append(options, giraffe.WithSampleRate(0))
+		})
+
+		It("does not log the successful requests", func() {
+			serve(http.StatusOK)
+			Expect(logger.InfoCallCount()).To(Equal(0))
+		})
+
+		It("always logs the failed requests", func() {
+			serve(http.StatusNotFound)
+			serve(http.StatusInternalServerError)
+			Expect(logger.WarnCallCount()).To(Equal(1))
+			Expect(logger.ErrorCallCount()).To(Equal(1))
+		})
+
+		Context("when the slow threshold is configured", func() {
+			BeforeEach(func() {
+				options = append(options, giraffe.WithSlowThreshold(time.Millisecond))
+			})
+
+			It("always logs the slow requests", func() {
+				logHandler(httptest.NewRecorder(), request, func(w http.ResponseWriter, req *http.Request) {
+					time.Sleep(2 * time.Millisecond)
+				})
+				serve(http.StatusOK)
+
+				Expect(logger.InfoCallCount()).To(Equal(1))
+			})
+		})
+	})
+
+	Context("when the thresholds are configured", func() {
+		BeforeEach(func() {
+			options = append(options, giraffe.WithLevelThresholds(300, 404))
+		})
+
+		It("logs the requests at configured levels", func() {
+			serve(http.StatusFound)
+			serve(http.StatusNotFound)
+			Expect(logger.WarnCallCount()).To(Equal(1))
+			Expect(logger.ErrorCallCount()).To(Equal(1))
+		})
+	})
 })
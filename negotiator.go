@@ -0,0 +1,178 @@
+package giraffe
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrNotAcceptable is returned when the model cannot be encoded in any of the accepted formats
+var ErrNotAcceptable = errors.New("None of the accepted content types can be produced")
+
+// HTTPNegotiator encodes a model in a format accepted by the request
+type HTTPNegotiator struct {
+	encoder *HTTPEncoder
+	request *http.Request
+}
+
+// Encode encodes a model as JSON, XML, plain text, HTML or binary data
+// depending on the Accept header of the request
+func (neg *HTTPNegotiator) Encode(model Model) error {
+	addVary(neg.encoder.writer, Accept)
+	return neg.encode(model, offers(model))
+}
+
+func (neg *HTTPNegotiator) encode(model Model, offers []string) error {
+	switch NegotiateContentType(neg.request.Header.Get(Accept), offers...) {
+	case ContentJSON:
+		return neg.encoder.EncodeJSON(model)
+	case ContentXML:
+		buffer := getBuffer()
+		defer putBuffer(buffer)
+
+		if err := xml.NewEncoder(buffer).Encode(model); err != nil {
+			// encoding/xml cannot marshal some models, e.g. maps
+			return neg.encode(model, without(offers, ContentXML))
+		}
+
+		setContentType(neg.encoder.writer, ContentXML)
+		_, err := buffer.WriteTo(neg.encoder.writer)
+		if err != nil {
+			neg.encoder.fail(http.StatusInternalServerError, "Unable to encode the model as XML data", err)
+		}
+		return err
+	case ContentText:
+		return neg.encoder.EncodeText(text(model))
+	case ContentHTML:
+		return neg.encoder.encodeHTML(model)
+	case ContentBinary:
+		return neg.encoder.EncodeData(model.([]byte))
+	default:
+		neg.encoder.fail(http.StatusNotAcceptable, "Unable to encode the model as any of the accepted content types", ErrNotAcceptable)
+		return ErrNotAcceptable
+	}
+}
+
+// NewHTTPNegotiator creates a new negotiator for concrete writer and request
+func NewHTTPNegotiator(writer http.ResponseWriter, request *http.Request, options ...EncoderOption) *HTTPNegotiator {
+	return &HTTPNegotiator{
+		encoder: NewHTTPEncoder(writer, append([]EncoderOption{WithRequest(request)}, options...)...),
+		request: request,
+	}
+}
+
+// NegotiateContentType returns the offer that best matches the Accept header.
+// The first offer is returned when the header is empty. An empty string is
+// returned when none of the offers is acceptable.
+func NegotiateContentType(accept string, offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+
+	ranges := parseAccept(accept)
+
+	var (
+		best        string
+		bestQuality float64
+		bestSpecial = -1
+	)
+
+	for _, offer := range offers {
+		quality, special := -1.0, -1
+		for _, r := range ranges {
+			if r.special > special && r.match(offer) {
+				quality, special = r.quality, r.special
+			}
+		}
+
+		if quality <= 0 {
+			continue
+		}
+
+		if quality > bestQuality || (quality == bestQuality && special > bestSpecial) {
+			best, bestQuality, bestSpecial = offer, quality, special
+		}
+	}
+
+	return best
+}
+
+type mediaRange struct {
+	kind    string
+	subtype string
+	quality float64
+	special int
+}
+
+func (r *mediaRange) match(offer string) bool {
+	kind, subtype := splitMediaType(offer)
+	return (r.kind == "*" || r.kind == kind) && (r.subtype == "*" || r.subtype == subtype)
+}
+
+func parseAccept(accept string) []*mediaRange {
+	ranges := []*mediaRange{}
+
+	for _, field := range strings.Split(accept, ",") {
+		params := strings.Split(field, ";")
+		kind, subtype := splitMediaType(params[0])
+		if kind == "" || subtype == "" {
+			continue
+		}
+
+		r := &mediaRange{kind: kind, subtype: subtype, quality: quality(params[1:])}
+		switch {
+		case kind == "*":
+			r.special = 0
+		case subtype == "*":
+			r.special = 1
+		default:
+			r.special = 2
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	return ranges
+}
+
+func splitMediaType(mediaType string) (string, string) {
+	kind, subtype, _ := strings.Cut(strings.ToLower(strings.TrimSpace(mediaType)), "/")
+	return strings.TrimSpace(kind), strings.TrimSpace(subtype)
+}
+
+func offers(model Model) []string {
+	offers := []string{ContentJSON, ContentXML, ContentText, ContentHTML}
+	if _, ok := model.([]byte); ok {
+		offers = append(offers, ContentBinary)
+	}
+	return offers
+}
+
+func without(offers []string, offer string) []string {
+	filtered := []string{}
+	for _, value := range offers {
+		if value != offer {
+			filtered = append(filtered, value)
+		}
+	}
+	return filtered
+}
+
+func text(model Model) string {
+	switch value := model.(type) {
+	case string:
+		return value
+	case []byte:
+		return string(value)
+	case fmt.Stringer:
+		return value.String()
+	default:
+		return fmt.Sprintf("%v", model)
+	}
+}
@@ -0,0 +1,155 @@
+package giraffe_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/svett/giraffe"
+)
+
+type person struct {
+	Name string `json:"name" xml:"name"`
+}
+
+var _ = Describe("HTTPNegotiator", func() {
+	var (
+		negotiator *giraffe.HTTPNegotiator
+		recorder   *httptest.ResponseRecorder
+		request    *http.Request
+	)
+
+	BeforeEach(func() {
+		recorder = httptest.NewRecorder()
+
+		var err error
+		request, err = http.NewRequest("GET", "http://example.com/people/1", nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		negotiator = giraffe.NewHTTPNegotiator(recorder, request)
+	})
+
+	Context("when the request does not have Accept header", func() {
+		It("encodes the model as json", func() {
+			Expect(negotiator.Encode(&person{Name: "root"})).To(Succeed())
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json; charset=UTF-8"))
+
+			var model person
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &model)).To(Succeed())
+			Expect(model.Name).To(Equal("root"))
+		})
+	})
+
+	Context("when the request accepts xml", func() {
+		BeforeEach(func() {
+			request.Header.Set("Accept", "application/xml")
+		})
+
+		It("encodes the model as xml", func() {
+			Expect(negotiator.Encode(&person{Name: "root"})).To(Succeed())
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/xml; charset=UTF-8"))
+			Expect(recorder.Body.String()).To(Equal("<person><name>root</name></person>"))
+		})
+
+		It("does not encode a model that cannot be encoded as xml", func() {
+			Expect(negotiator.Encode(map[string]string{"name": "root"})).To(MatchError(giraffe.ErrNotAcceptable))
+			Expect(recorder.Code).To(Equal(http.StatusNotAcceptable))
+		})
+
+		Context("when the request accepts json as well", func() {
+			BeforeEach(func() {
+				request.Header.Set("Accept", "application/xml, application/json;q=0.5")
+			})
+
+			It("encodes a model that cannot be encoded as xml as json", func() {
+				Expect(negotiator.Encode(map[string]string{"name": "root"})).To(Succeed())
+				Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json; charset=UTF-8"))
+				Expect(recorder.Body.String()).To(MatchJSON(`{"name":"root"}`))
+			})
+		})
+	})
+
+	Context("when the request prefers plain text by quality", func() {
+		BeforeEach(func() {
+			request.Header.Set("Accept", "application/json;q=0.5, text/plain;q=0.9, */*;q=0.1")
+		})
+
+		It("encodes the model as text", func() {
+			Expect(negotiator.Encode("hello")).To(Succeed())
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("text/plain; charset=UTF-8"))
+			Expect(recorder.Body.String()).To(Equal("hello"))
+		})
+	})
+
+	Context("when the request accepts html", func() {
+		BeforeEach(func() {
+			request.Header.Set("Accept", "text/html,application/xhtml+xml")
+		})
+
+		It("encodes the model as escaped html", func() {
+			Expect(negotiator.Encode("<b>hello</b>")).To(Succeed())
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("text/html; charset=UTF-8"))
+			Expect(recorder.Body.String()).To(Equal("&lt;b&gt;hello&lt;/b&gt;"))
+		})
+	})
+
+	Context("when the request accepts binary data", func() {
+		BeforeEach(func() {
+			request.Header.Set("Accept", "application/octet-stream")
+		})
+
+		It("encodes an array of bytes", func() {
+			Expect(negotiator.Encode([]byte("gopher"))).To(Succeed())
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/octet-stream; charset=UTF-8"))
+			Expect(recorder.Body.String()).To(Equal("gopher"))
+		})
+
+		It("does not encode other models", func() {
+			Expect(negotiator.Encode("gopher")).To(MatchError(giraffe.ErrNotAcceptable))
+			Expect(recorder.Code).To(Equal(http.StatusNotAcceptable))
+		})
+	})
+
+	Context("when nothing matches the Accept header", func() {
+		BeforeEach(func() {
+			request.Header.Set("Accept", "image/png, */*;q=0")
+		})
+
+		It("returns an error", func() {
+			Expect(negotiator.Encode("hello")).To(MatchError(giraffe.ErrNotAcceptable))
+		})
+
+		It("has the correct status code", func() {
+			negotiator.Encode("hello")
+			Expect(recorder.Code).To(Equal(http.StatusNotAcceptable))
+		})
+	})
+
+	It("varies the response by Accept header", func() {
+		Expect(negotiator.Encode("hello")).To(Succeed())
+		Expect(recorder.Header().Get("Vary")).To(Equal("Accept"))
+	})
+})
+
+var _ = Describe("NegotiateContentType", func() {
+	It("returns the first offer when the header is empty", func() {
+		Expect(giraffe.NegotiateContentType("", "text/plain", "text/html")).To(Equal("text/plain"))
+	})
+
+	It("prefers the most specific media range", func() {
+		Expect(giraffe.NegotiateContentType("text/*;q=0.3, text/html;q=0.7", "text/plain", "text/html")).To(Equal("text/html"))
+	})
+
+	It("excludes offers with zero quality", func() {
+		Expect(giraffe.NegotiateContentType("*/*, text/plain;q=0", "text/plain", "text/html")).To(Equal("text/html"))
+	})
+
+	It("returns an empty string when nothing matches", func() {
+		Expect(giraffe.NegotiateContentType("image/png", "text/plain")).To(BeEmpty())
+	})
+})
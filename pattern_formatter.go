@@ -0,0 +1,187 @@
+package giraffe
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	// CommonLogFormat is the Apache Common Log Format
+	CommonLogFormat = `%h %l %u %t "%r" %>s %b`
+	// CombinedLogFormat is the Apache Combined Log Format
+	CombinedLogFormat = `%h %l %u %t "%r" %>s %b "%{Referer}i" "%{User-Agent}i"`
+)
+
+var (
+	// CommonLogFormatter formats the access log entries in Apache Common Log Format
+	CommonLogFormatter = NewPatternFormatter(CommonLogFormat)
+	// CombinedLogFormatter formats the access log entries in Apache Combined Log Format
+	CombinedLogFormatter = NewPatternFormatter(CombinedLogFormat)
+)
+
+type patternToken func(entry *AccessLogEntry) string
+
+// PatternFormatter formats the access log entries by Apache mod_log_config format string.
+// The following directives are supported:
+//
+//	%%        a percent sign
+//	%a, %h    remote IP address
+//	%l        remote logname (always "-")
+//	%u        remote user
+//	%t        time the request was received
+//	%r        first line of request
+//	%m        request method
+//	%U        URL path
+//	%q        query string prepended with "?"
+//	%H        request protocol
+//	%s, %>s   status code
+//	%b        size of response body or "-" when no bytes are sent
+//	%B        size of response body
+//	%D        time taken to serve the request in microseconds
+//	%T        time taken to serve the request in seconds
+//	%{Name}i  request header
+//	%{Name}o  response header
+type PatternFormatter struct {
+	tokens []patternToken
+}
+
+// NewPatternFormatter creates a new formatter for given format string
+func NewPatternFormatter(pattern string) *PatternFormatter {
+	formatter := &PatternFormatter{}
+	literal := &strings.Builder{}
+
+	flush := func() {
+		if literal.Len() == 0 {
+			return
+		}
+		text := literal.String()
+		literal.Reset()
+		formatter.tokens = append(formatter.tokens, func(*AccessLogEntry) string {
+			return text
+		})
+	}
+
+	for index := 0; index < len(pattern); index++ {
+		if pattern[index] != '%' || index+1 == len(pattern) {
+			literal.WriteByte(pattern[index])
+			continue
+		}
+
+		start := index
+		directive := pattern[index+1:]
+		argument := ""
+		if strings.HasPrefix(directive, "{") {
+			end := strings.Index(directive, "}")
+			if end == -1 || end+1 == len(directive) {
+				literal.WriteByte(pattern[index])
+				continue
+			}
+			argument = directive[1:end]
+			directive = directive[end+1:]
+			index += end + 1
+		}
+
+		if strings.HasPrefix(directive, ">") && len(directive) > 1 {
+			directive = directive[1:]
+			index++
+		}
+
+		token := patternDirective(directive[0], argument)
+		index++
+
+		if token == nil {
+			literal.WriteString(pattern[start : index+1])
+			continue
+		}
+
+		flush()
+		formatter.tokens = append(formatter.tokens, token)
+	}
+
+	flush()
+	return formatter
+}
+
+// Format formats an access log entry
+func (formatter *PatternFormatter) Format(entry *AccessLogEntry) string {
+	builder := &strings.Builder{}
+	for _, token := range formatter.tokens {
+		builder.WriteString(token(entry))
+	}
+	return builder.String()
+}
+
+func patternDirective(directive byte, argument string) patternToken {
+	switch directive {
+	case '%':
+		return func(*AccessLogEntry) string { return "%" }
+	case 'a', 'h':
+		return func(entry *AccessLogEntry) string { return dash(entry.RemoteIP) }
+	case 'l':
+		return func(*AccessLogEntry) string { return "-" }
+	case 'u':
+		return func(entry *AccessLogEntry) string { return dash(escape(entry.User)) }
+	case 't':
+		return func(entry *AccessLogEntry) string { return entry.Time.Format("[02/Jan/2006:15:04:05 -0700]") }
+	case 'r':
+		return func(entry *AccessLogEntry) string {
+			uri := entry.Path
+			if entry.Query != "" {
+				uri += "?" + entry.Query
+			}
+			return escape(entry.Method + " " + uri + " " + entry.Proto)
+		}
+	case 'm':
+		return func(entry *AccessLogEntry) string { return escape(entry.Method) }
+	case 'U':
+		return func(entry *AccessLogEntry) string { return escape(entry.Path) }
+	case 'q':
+		return func(entry *AccessLogEntry) string {
+			if entry.Query == "" {
+				return ""
+			}
+			return escape("?" + entry.Query)
+		}
+	case 'H':
+		return func(entry *AccessLogEntry) string { return escape(entry.Proto) }
+	case 's':
+		return func(entry *AccessLogEntry) string { return strconv.Itoa(entry.Status) }
+	case 'b':
+		return func(entry *AccessLogEntry) string {
+			if entry.Bytes == 0 {
+				return "-"
+			}
+			return strconv.FormatInt(entry.Bytes, 10)
+		}
+	case 'B':
+		return func(entry *AccessLogEntry) string { return strconv.FormatInt(entry.Bytes, 10) }
+	case 'D':
+		return func(entry *AccessLogEntry) string { return strconv.FormatInt(entry.Latency.Microseconds(), 10) }
+	case 'T':
+		return func(entry *AccessLogEntry) string { return strconv.FormatInt(int64(entry.Latency.Seconds()), 10) }
+	case 'i':
+		if argument == "" {
+			return nil
+		}
+		return func(entry *AccessLogEntry) string { return dash(escape(entry.RequestHeader.Get(argument))) }
+	case 'o':
+		if argument == "" {
+			return nil
+		}
+		return func(entry *AccessLogEntry) string { return dash(escape(entry.ResponseHeader.Get(argument))) }
+	default:
+		return nil
+	}
+}
+
+func dash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func escape(value string) string {
+	quoted := strconv.Quote(value)
+	return quoted[1 : len(quoted)-1]
+}
@@ -0,0 +1,63 @@
+package giraffe_test
+
+import (
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/svett/giraffe"
+)
+
+var _ = Describe("PatternFormatter", func() {
+	var entry *giraffe.AccessLogEntry
+
+	BeforeEach(func() {
+		entry = &giraffe.AccessLogEntry{
+			Time:      time.Date(2016, time.July, 3, 10, 20, 30, 0, time.FixedZone("EEST", 3*60*60)),
+			Method:    "GET",
+			Path:      "/apache_pb.gif",
+			Query:     "size=2",
+			Proto:     "HTTP/1.0",
+			Status:    200,
+			Latency:   2500 * time.Microsecond,
+			Bytes:     2326,
+			RemoteIP:  "127.0.0.1",
+			User:      "frank",
+			UserAgent: "Mozilla/4.08",
+			Referer:   "http://www.example.com/start.html",
+			RequestHeader: http.Header{
+				"Referer":    []string{"http://www.example.com/start.html"},
+				"User-Agent": []string{"Mozilla/4.08 \"quoted\""},
+			},
+			ResponseHeader: http.Header{
+				"Content-Type": []string{"image/gif"},
+			},
+		}
+	})
+
+	It("formats the entry in Common Log Format", func() {
+		Expect(giraffe.CommonLogFormatter.Format(entry)).To(Equal(
+			`127.0.0.1 - frank [03/Jul/2016:10:20:30 +0300] "GET /apache_pb.gif?size=2 HTTP/1.0" 200 2326`,
+		))
+	})
+
+	It("formats the entry in Combined Log Format", func() {
+		Expect(giraffe.CombinedLogFormatter.Format(entry)).To(Equal(
+			`127.0.0.1 - frank [03/Jul/2016:10:20:30 +0300] "GET /apache_pb.gif?size=2 HTTP/1.0" 200 2326 ` +
+				`"http://www.example.com/start.html" "Mozilla/4.08 \"quoted\""`,
+		))
+	})
+
+	It("formats the entry with custom format", func() {
+		formatter := giraffe.NewPatternFormatter(`%m %U%q %s %B %Dus %Ts %{Content-Type}o %{X-Missing}i 100%% %z`)
+		Expect(formatter.Format(entry)).To(Equal(`GET /apache_pb.gif?size=2 200 2326 2500us 0s image/gif - 100% %z`))
+	})
+
+	It("writes dash when the values are empty", func() {
+		entry.User = ""
+		entry.Bytes = 0
+		Expect(giraffe.NewPatternFormatter("%u %b").Format(entry)).To(Equal("- -"))
+	})
+})
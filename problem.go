@@ -0,0 +1,141 @@
+package giraffe
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+var defaultErrorHandler ErrorHandler = ProblemErrorHandler{}
+
+// Problem represents RFC 7807 problem details of an error response
+type Problem struct {
+	// Type is an URI reference that identifies the problem type. Defaults to "about:blank".
+	Type string `json:"type,omitempty"`
+	// Title is a short summary of the problem type
+	Title string `json:"title,omitempty"`
+	// Status is the HTTP status code
+	Status int `json:"status,omitempty"`
+	// Detail is an explanation specific to this occurrence of the problem
+	Detail string `json:"detail,omitempty"`
+	// Instance is an URI reference that identifies this occurrence of the problem
+	Instance string `json:"instance,omitempty"`
+	// Extensions are additional members of the problem details
+	Extensions map[string]interface{} `json:"-"`
+	// Err is the error that caused the problem. It is never sent to the client.
+	Err error `json:"-"`
+}
+
+// NewProblem creates a new problem for given status code. The request ID
+// of the request context is added as "request_id" extension.
+func NewProblem(request *http.Request, status int, detail string, err error) *Problem {
+	problem := &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Err:    err,
+	}
+
+	if request != nil && request.URL != nil {
+		problem.Instance = request.URL.Path
+	}
+
+	if id := requestID(request); id != "" {
+		problem.Extensions = map[string]interface{}{"request_id": id}
+	}
+
+	return problem
+}
+
+// newResponseProblem creates a new problem for the response. The request ID
+// is read from the response writer when the request does not carry it.
+func newResponseProblem(writer http.ResponseWriter, request *http.Request, status int, detail string, err error) *Problem {
+	problem := NewProblem(request, status, detail, err)
+
+	if problem.Extensions == nil {
+		if id := responseRequestID(writer); id != "" {
+			problem.Extensions = map[string]interface{}{"request_id": id}
+		}
+	}
+
+	return problem
+}
+
+// Error returns the problem detail
+func (problem *Problem) Error() string {
+	if problem.Detail != "" {
+		return problem.Detail
+	}
+	return problem.Title
+}
+
+// MarshalJSON encodes the problem together with its extensions
+func (problem *Problem) MarshalJSON() ([]byte, error) {
+	type details Problem
+
+	data, err := json.Marshal((*details)(problem))
+	if err != nil || len(problem.Extensions) == 0 {
+		return data, err
+	}
+
+	members := map[string]interface{}{}
+	for key, value := range problem.Extensions {
+		members[key] = value
+	}
+
+	if err = json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(members)
+}
+
+//go:generate counterfeiter -o fakes/fake_error_handler.go . ErrorHandler
+
+// ErrorHandler writes an error response
+type ErrorHandler interface {
+	HandleError(writer http.ResponseWriter, request *http.Request, problem *Problem)
+}
+
+// ErrorHandlerFunc converts a func into ErrorHandler
+type ErrorHandlerFunc func(writer http.ResponseWriter, request *http.Request, problem *Problem)
+
+// HandleError writes an error response
+func (f ErrorHandlerFunc) HandleError(writer http.ResponseWriter, request *http.Request, problem *Problem) {
+	f(writer, request, problem)
+}
+
+// ProblemErrorHandler writes the error response as application/problem+json
+type ProblemErrorHandler struct{}
+
+// HandleError writes an error response
+func (ProblemErrorHandler) HandleError(writer http.ResponseWriter, request *http.Request, problem *Problem) {
+	header := writer.Header()
+	header.Del("Content-Length")
+	header.Set(ContentType, ContentProblemJSON+"; charset="+ContentDefaultCharset)
+	header.Set("X-Content-Type-Options", "nosniff")
+
+	writer.WriteHeader(problem.Status)
+	json.NewEncoder(writer).Encode(problem)
+}
+
+// TextErrorHandler writes the error response as plain text
+type TextErrorHandler struct{}
+
+// HandleError writes an error response
+func (TextErrorHandler) HandleError(writer http.ResponseWriter, request *http.Request, problem *Problem) {
+	http.Error(writer, problem.Error(), problem.Status)
+}
+
+// SetErrorHandler sets the default error handler
+func SetErrorHandler(handler ErrorHandler) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultErrorHandler = handler
+}
+
+func errorHandler() ErrorHandler {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultErrorHandler
+}
@@ -0,0 +1,94 @@
+package giraffe_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/svett/giraffe"
+	"github.com/svett/giraffe/fakes"
+)
+
+var _ = Describe("Problem", func() {
+	var request *http.Request
+
+	BeforeEach(func() {
+		var err error
+		request, err = http.NewRequest("GET", "http://example.com/users/1?token=secret", nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("creates a problem for given status code", func() {
+		problem := giraffe.NewProblem(request, http.StatusNotFound, "The user does not exist", nil)
+		Expect(problem.Type).To(Equal("about:blank"))
+		Expect(problem.Title).To(Equal("Not Found"))
+		Expect(problem.Status).To(Equal(http.StatusNotFound))
+		Expect(problem.Detail).To(Equal("The user does not exist"))
+		Expect(problem.Instance).To(Equal("/users/1"))
+	})
+
+	It("encodes the extensions as members", func() {
+		problem := giraffe.NewProblem(nil, http.StatusConflict, "", errors.New("oh no!"))
+		problem.Extensions = map[string]interface{}{"balance": 30}
+
+		data, err := json.Marshal(problem)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(MatchJSON(`{"type":"about:blank","title":"Conflict","status":409,"balance":30}`))
+	})
+})
+
+var _ = Describe("ProblemErrorHandler", func() {
+	var recorder *httptest.ResponseRecorder
+
+	BeforeEach(func() {
+		recorder = httptest.NewRecorder()
+		recorder.Header().Set("Content-Type", "application/json")
+
+		problem := giraffe.NewProblem(nil, http.StatusInternalServerError, "Unable to encode", errors.New("oh no!"))
+		giraffe.ProblemErrorHandler{}.HandleError(recorder, nil, problem)
+	})
+
+	It("writes the problem details", func() {
+		Expect(recorder.Body.String()).To(MatchJSON(`{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"Unable to encode"}`))
+	})
+
+	It("has the correct headers", func() {
+		Expect(recorder.HeaderMap).To(HaveKeyWithValue("Content-Type", []string{"application/problem+json; charset=UTF-8"}))
+		Expect(recorder.HeaderMap).To(HaveKeyWithValue("X-Content-Type-Options", []string{"nosniff"}))
+	})
+
+	It("has the correct status code", func() {
+		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+	})
+})
+
+var _ = Describe("TextErrorHandler", func() {
+	It("writes the problem detail as plain text", func() {
+		recorder := httptest.NewRecorder()
+		problem := giraffe.NewProblem(nil, http.StatusBadRequest, "Invalid name", nil)
+		giraffe.TextErrorHandler{}.HandleError(recorder, nil, problem)
+
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(recorder.Body.String()).To(Equal("Invalid name\n"))
+		Expect(recorder.HeaderMap).To(HaveKeyWithValue("Content-Type", []string{"text/plain; charset=utf-8"}))
+	})
+})
+
+var _ = Describe("SetErrorHandler", func() {
+	AfterEach(func() {
+		giraffe.SetErrorHandler(giraffe.ProblemErrorHandler{})
+	})
+
+	It("sets the default error handler", func() {
+		handler := new(fakes.FakeErrorHandler)
+		giraffe.SetErrorHandler(handler)
+
+		encoder := giraffe.NewHTTPEncoder(httptest.NewRecorder())
+		Expect(encoder.EncodeJSON(make(chan int))).NotTo(Succeed())
+		Expect(handler.HandleErrorCallCount()).To(Equal(1))
+	})
+})
@@ -0,0 +1,263 @@
+package giraffe
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// DefaultSourceContext is the number of source lines shown around the panic in development mode
+const DefaultSourceContext = 5
+
+var developmentTemplate = template.Must(template.New("panic").Parse(`<!DOCTYPE html>
+<html>
+<head><title>{{.Problem.Title}}</title></head>
+<body>
+<h1>{{.Problem.Title}}</h1>
+<p>{{.Problem.Detail}}</p>
+{{range .Panic.Frames}}<h3>{{.Function}}</h3>
+<p>{{.File}}:{{.Line}}</p>
+{{if .Source}}<pre>{{range .Source}}{{if .Current}}<strong>{{printf "%5d" .Number}} {{.Code}}</strong>{{else}}{{printf "%5d" .Number}} {{.Code}}{{end}}
+{{end}}</pre>{{end}}
+{{end}}</body>
+</html>
+`))
+
+// PanicError is an error that describes a recovered panic
+type PanicError struct {
+	// Value passed to panic
+	Value interface{} `json:"-"`
+	// Stack is the formatted stack trace of the panicking goroutine
+	Stack []byte `json:"-"`
+	// Frames of the stack trace
+	Frames []StackFrame `json:"frames"`
+}
+
+// Error returns the panic value
+func (err *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", err.Value)
+}
+
+// StackFrame is a frame of the stack trace
+type StackFrame struct {
+	// Function name
+	Function string `json:"function"`
+	// File path
+	File string `json:"file"`
+	// Line number
+	Line int `json:"line"`
+	// Source lines around the line. They are collected only in development mode.
+	Source []SourceLine `json:"source,omitempty"`
+}
+
+// SourceLine is a line of source code
+type SourceLine struct {
+	// Number of the line
+	Number int `json:"number"`
+	// Code of the line
+	Code string `json:"code"`
+	// Current is true for the line of the stack frame
+	Current bool `json:"current,omitempty"`
+}
+
+// RecoveryModel is the model of the error template
+type RecoveryModel struct {
+	// Problem details of the error response
+	Problem *Problem
+	// Panic that has been recovered
+	Panic *PanicError
+	// Development is true in development mode
+	Development bool
+}
+
+// RecoveryOption configures the recovery middleware
+type RecoveryOption func(*recovery)
+
+// WithDevelopmentMode shows the panic value, the stack trace and the source code
+// in the error response. It must not be enabled in production.
+func WithDevelopmentMode(enabled bool) RecoveryOption {
+	return func(recovery *recovery) {
+		recovery.development = enabled
+	}
+}
+
+// WithErrorTemplate renders the HTML template of the provider with RecoveryModel
+// when the client accepts HTML
+func WithErrorTemplate(provider HTMLTemplateProvider, template string) RecoveryOption {
+	return func(recovery *recovery) {
+		recovery.provider = provider
+		recovery.template = template
+	}
+}
+
+// WithRecoveryErrorHandler sets the handler that writes the error responses. Defaults to the default error handler.
+func WithRecoveryErrorHandler(handler ErrorHandler) RecoveryOption {
+	return func(recovery *recovery) {
+		recovery.errorHandler = handler
+	}
+}
+
+// NewHTTPRecovery recovers from panics of the next handlers. The panic is
+// logged together with its stack trace and a 500 response is written.
+func NewHTTPRecovery(logger Logger, options ...RecoveryOption) HandlerFunc {
+	recovery := &recovery{
+		logger:       logger,
+		errorHandler: errorHandler(),
+	}
+
+	for _, option := range options {
+		option(recovery)
+	}
+
+	return recovery.handle
+}
+
+type recovery struct {
+	logger       Logger
+	errorHandler ErrorHandler
+	provider     HTMLTemplateProvider
+	template     string
+	development  bool
+}
+
+func (recovery *recovery) handle(w http.ResponseWriter, request *http.Request, next http.HandlerFunc) {
+	writer, ok := w.(ResponseWriter)
+	if !ok {
+		writer = NewResponseWriter(w)
+	}
+
+	defer func() {
+		value := recover()
+		if value == nil {
+			return
+		}
+
+		if value == http.ErrAbortHandler {
+			panic(value)
+		}
+
+		err := recovery.newPanicError(value)
+		recovery.logger.Println(fmt.Sprintf("PANIC %s %s: %v\n%s", request.Method, request.URL.Path, value, err.Stack))
+
+		if writer.Written() {
+			// the response has been already started
+			return
+		}
+
+		recovery.respond(writer, request, err)
+	}()
+
+	next(writer, request)
+}
+
+func (recovery *recovery) respond(writer http.ResponseWriter, request *http.Request, err *PanicError) {
+	problem := NewProblem(request, http.StatusInternalServerError, "", err)
+	if recovery.development {
+		problem.Detail = err.Error()
+		if problem.Extensions == nil {
+			problem.Extensions = map[string]interface{}{}
+		}
+		problem.Extensions["stack"] = err.Frames
+	}
+
+	header := writer.Header()
+	for _, key := range []string{ContentType, ContentEncoding, ContentLength} {
+		header.Del(key)
+	}
+
+	if NegotiateContentType(request.Header.Get(Accept), ContentProblemJSON, ContentHTML) == ContentHTML {
+		model := &RecoveryModel{Problem: problem, Panic: err, Development: recovery.development}
+		if recovery.renderHTML(writer, model) {
+			return
+		}
+	}
+
+	NewHTTPEncoder(writer, WithRequest(request), WithErrorHandler(recovery.errorHandler)).EncodeProblem(problem)
+}
+
+func (recovery *recovery) renderHTML(writer http.ResponseWriter, model *RecoveryModel) bool {
+	buffer := &bytes.Buffer{}
+
+	switch {
+	case recovery.provider != nil:
+		templates, err := recovery.provider.Provide()
+		if err != nil || templates.ExecuteTemplate(buffer, recovery.template, model) != nil {
+			return false
+		}
+	case recovery.development:
+		if developmentTemplate.Execute(buffer, model) != nil {
+			return false
+		}
+	default:
+		return false
+	}
+
+	setContentType(writer, ContentHTML)
+	writer.WriteHeader(model.Problem.Status)
+	buffer.WriteTo(writer)
+	return true
+}
+
+func (recovery *recovery) newPanicError(value interface{}) *PanicError {
+	err := &PanicError{
+		Value:  value,
+		Stack:  debug.Stack(),
+		Frames: []StackFrame{},
+	}
+
+	pc := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pc[:runtime.Callers(4, pc)])
+
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			stackFrame := StackFrame{
+				Function: frame.Function,
+				File:     frame.File,
+				Line:     frame.Line,
+			}
+
+			if recovery.development {
+				stackFrame.Source = source(frame.File, frame.Line, DefaultSourceContext)
+			}
+
+			err.Frames = append(err.Frames, stackFrame)
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	return err
+}
+
+// source returns the lines of the file around given line
+func source(path string, line, context int) []SourceLine {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	lines := []SourceLine{}
+	scanner := bufio.NewScanner(file)
+
+	for number := 1; scanner.Scan() && number <= line+context; number++ {
+		if number >= line-context {
+			lines = append(lines, SourceLine{
+				Number:  number,
+				Code:    scanner.Text(),
+				Current: number == line,
+			})
+		}
+	}
+
+	return lines
+}
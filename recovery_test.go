@@ -0,0 +1,169 @@
+package giraffe_test
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/svett/giraffe"
+	"github.com/svett/giraffe/fakes"
+)
+
+var _ = Describe("Recovery", func() {
+	var (
+		logger   *fakes.FakeLogger
+		options  []giraffe.RecoveryOption
+		request  *http.Request
+		recorder *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		logger = new(fakes.FakeLogger)
+		options = []giraffe.RecoveryOption{}
+		request = httptest.NewRequest("GET", "http://example.com/users", nil)
+		recorder = httptest.NewRecorder()
+	})
+
+	serve := func(handler http.HandlerFunc) {
+		giraffe.NewHTTPRecovery(logger, options...)(recorder, request, handler)
+	}
+
+	panics := func(w http.ResponseWriter, req *http.Request) {
+		panic("oh no!")
+	}
+
+	It("processes the next request", func() {
+		serve(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+		})
+
+		Expect(recorder.Code).To(Equal(http.StatusCreated))
+		Expect(logger.PrintlnCallCount()).To(Equal(0))
+	})
+
+	It("logs the panic with the stack trace", func() {
+		serve(panics)
+
+		Expect(logger.PrintlnCallCount()).To(Equal(1))
+		msg := logger.PrintlnArgsForCall(0)[0].(string)
+		Expect(msg).To(ContainSubstring("PANIC GET /users: oh no!"))
+		Expect(msg).To(ContainSubstring("recovery_test.go"))
+	})
+
+	It("writes the problem details", func() {
+		serve(panics)
+
+		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/problem+json; charset=UTF-8"))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"type":"about:blank","title":"Internal Server Error","status":500,"instance":"/users"}`))
+	})
+
+	It("does not write a response when the response has been started", func() {
+		serve(func(w http.ResponseWriter, req *http.Request) {
+			w.Write([]byte("hello"))
+			panic("oh no!")
+		})
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(Equal("hello"))
+		Expect(logger.PrintlnCallCount()).To(Equal(1))
+	})
+
+	It("propagates http.ErrAbortHandler", func() {
+		Expect(func() {
+			serve(func(w http.ResponseWriter, req *http.Request) {
+				panic(http.ErrAbortHandler)
+			})
+		}).To(PanicWith(http.ErrAbortHandler))
+	})
+
+	It("uses the error handler", func() {
+		handler := new(fakes.FakeErrorHandler)
+		options = append(options, giraffe.WithRecoveryErrorHandler(handler))
+		serve(panics)
+
+		Expect(handler.HandleErrorCallCount()).To(Equal(1))
+		_, _, problem := handler.HandleErrorArgsForCall(0)
+		Expect(problem.Status).To(Equal(http.StatusInternalServerError))
+
+		var err *giraffe.PanicError
+		Expect(errors.As(problem.Err, &err)).To(BeTrue())
+		Expect(err.Value).To(Equal("oh no!"))
+	})
+
+	Context("when the development mode is enabled", func() {
+		BeforeEach(func() {
+			options = append(options, giraffe.WithDevelopmentMode(true))
+		})
+
+		It("writes the stack and the source code", func() {
+			serve(panics)
+
+			var problem map[string]interface{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &problem)).To(Succeed())
+			Expect(problem).To(HaveKeyWithValue("detail", "panic: oh no!"))
+			Expect(problem).To(HaveKey("stack"))
+			Expect(recorder.Body.String()).To(ContainSubstring(`panic(\"oh no!\")`))
+		})
+
+		It("writes a HTML page when the client accepts HTML", func() {
+			request.Header.Set("Accept", "text/html,application/xhtml+xml,*/*;q=0.8")
+			serve(panics)
+
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("text/html; charset=UTF-8"))
+			Expect(recorder.Body.String()).To(ContainSubstring("panic: oh no!"))
+			Expect(recorder.Body.String()).To(ContainSubstring("recovery_test.go"))
+		})
+	})
+
+	Context("when the error template is configured", func() {
+		var provider *fakes.FakeHTMLTemplateProvider
+
+		BeforeEach(func() {
+			provider = new(fakes.FakeHTMLTemplateProvider)
+			provider.ProvideReturns(template.Must(template.New("500").Parse(`<h1>{{.Problem.Title}}</h1>`)), nil)
+			options = append(options, giraffe.WithErrorTemplate(provider, "500"))
+			request.Header.Set("Accept", "text/html")
+		})
+
+		It("renders the template", func() {
+			serve(panics)
+
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("text/html; charset=UTF-8"))
+			Expect(recorder.Body.String()).To(Equal("<h1>Internal Server Error</h1>"))
+		})
+
+		It("writes the problem details when the template cannot be rendered", func() {
+			provider.ProvideReturns(nil, errors.New("oh no!"))
+			serve(panics)
+
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/problem+json; charset=UTF-8"))
+		})
+
+		It("writes the problem details when the client does not accept HTML", func() {
+			request.Header.Set("Accept", "application/json")
+			serve(panics)
+
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/problem+json; charset=UTF-8"))
+		})
+	})
+
+	It("is logged by the HTTP logger", func() {
+		accessLogger := new(fakes.FakeLogger)
+		chain := giraffe.NewChain(
+			giraffe.NewHTTPLogger(accessLogger, false, giraffe.WithFormatter(giraffe.NewPatternFormatter("%s"))),
+			giraffe.NewHTTPRecovery(logger),
+		)
+
+		chain.ThenFunc(panics).ServeHTTP(recorder, request)
+		Expect(accessLogger.PrintlnArgsForCall(0)[0]).To(Equal("500"))
+	})
+})
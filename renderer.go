@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"html/template"
 	"net/http"
+	"strconv"
 	"sync"
 )
 
@@ -27,43 +28,146 @@ type HTMLTemplateProvider interface {
 	Provide() (*template.Template, error)
 }
 
+// RendererOption configures the HTMLTemplateRenderer
+type RendererOption func(*HTMLTemplateRenderer)
+
+// WithRendererRequest sets the request that is served by the renderer
+func WithRendererRequest(request *http.Request) RendererOption {
+	return func(renderer *HTMLTemplateRenderer) {
+		renderer.request = request
+	}
+}
+
+// WithRendererErrorHandler sets the handler that writes the error responses of the renderer
+func WithRendererErrorHandler(handler ErrorHandler) RendererOption {
+	return func(renderer *HTMLTemplateRenderer) {
+		renderer.errorHandler = handler
+	}
+}
+
+// WithLayout sets the default layout that wraps the rendered templates
+func WithLayout(layout string) RendererOption {
+	return func(renderer *HTMLTemplateRenderer) {
+		renderer.layout = layout
+	}
+}
+
+// WithRendererErrorTemplate sets the template that is rendered with the *Problem
+// when the rendering fails. The error handler is used when the error template fails.
+func WithRendererErrorTemplate(template string) RendererOption {
+	return func(renderer *HTMLTemplateRenderer) {
+		renderer.errorTemplate = template
+	}
+}
+
+// WithStreaming executes the templates without layout directly into the writer
+// instead of a buffer. It reduces the memory of huge pages, but a failed
+// rendering leaves a partially written response.
+func WithStreaming(enabled bool) RendererOption {
+	return func(renderer *HTMLTemplateRenderer) {
+		renderer.streaming = enabled
+	}
+}
+
 // HTMLTemplateRenderer renders a templates of repository
 type HTMLTemplateRenderer struct {
-	writer   http.ResponseWriter
-	provider HTMLTemplateProvider
+	writer        http.ResponseWriter
+	request       *http.Request
+	provider      HTMLTemplateProvider
+	errorHandler  ErrorHandler
+	layout        string
+	errorTemplate string
+	streaming     bool
 }
 
-// Render renders a template
+// Render renders a template within the default layout
 func (renderer *HTMLTemplateRenderer) Render(template string, model Model) error {
+	return renderer.RenderWithLayout(renderer.layout, template, model)
+}
+
+// RenderWithLayout renders a template within given layout. The layout
+// renders the template by calling {{yield}} once or more. The template is rendered
+// without layout when the layout is empty. The response is written only
+// when the rendering succeeds unless the streaming is enabled.
+func (renderer *HTMLTemplateRenderer) RenderWithLayout(layout, template string, model Model) error {
 	templates, err := renderer.provider.Provide()
 	if err != nil {
-		renderer.errorf(template, err)
+		renderer.errorf(templates, template, err)
 		return err
 	}
-	setContentType(renderer.writer, ContentHTML)
-	err = templates.ExecuteTemplate(renderer.writer, template, model)
+
+	if renderer.streaming && layout == "" {
+		setContentType(renderer.writer, ContentHTML)
+		err = templates.ExecuteTemplate(renderer.writer, template, model)
+		if err != nil {
+			renderer.errorf(templates, template, err)
+			return err
+		}
+		return nil
+	}
+
+	buffer := getBuffer()
+	defer putBuffer(buffer)
+
+	if layout != "" {
+		err = executeLayout(buffer, templates, layout, template, model)
+	} else {
+		err = templates.ExecuteTemplate(buffer, template, model)
+	}
+
 	if err != nil {
-		renderer.errorf(template, err)
+		renderer.errorf(templates, template, err)
 		return err
 	}
-	return nil
+
+	setContentType(renderer.writer, ContentHTML)
+	renderer.writer.Header().Set(ContentLength, strconv.Itoa(buffer.Len()))
+	_, err = buffer.WriteTo(renderer.writer)
+	return err
 }
 
-func (renderer *HTMLTemplateRenderer) errorf(template string, err error) {
-	http.Error(renderer.writer, fmt.Sprintf("Unable to render '%s' html template: %s", template, err.Error()), http.StatusInternalServerError)
+func (renderer *HTMLTemplateRenderer) errorf(templates *template.Template, name string, err error) {
+	problem := newResponseProblem(renderer.writer, renderer.request, http.StatusInternalServerError, fmt.Sprintf("Unable to render '%s' html template", name), err)
+
+	if templates != nil && renderer.errorTemplate != "" {
+		buffer := getBuffer()
+		defer putBuffer(buffer)
+
+		if templates.ExecuteTemplate(buffer, renderer.errorTemplate, problem) == nil {
+			header := renderer.writer.Header()
+			header.Set(ContentType, ContentHTML+"; charset="+ContentDefaultCharset)
+			header.Set(ContentLength, strconv.Itoa(buffer.Len()))
+			renderer.writer.WriteHeader(problem.Status)
+			buffer.WriteTo(renderer.writer)
+			return
+		}
+	}
+
+	renderer.errorHandler.HandleError(renderer.writer, renderer.request, problem)
 }
 
 // NewHTMLTemplateRendererWithProvider create a new HTMLTemplateRenderer for specific provider
-func NewHTMLTemplateRendererWithProvider(writer http.ResponseWriter, provider HTMLTemplateProvider) *HTMLTemplateRenderer {
-	return &HTMLTemplateRenderer{
-		writer:   writer,
-		provider: provider,
+func NewHTMLTemplateRendererWithProvider(writer http.ResponseWriter, provider HTMLTemplateProvider, options ...RendererOption) *HTMLTemplateRenderer {
+	renderer := &HTMLTemplateRenderer{
+		writer:       writer,
+		provider:     provider,
+		errorHandler: errorHandler(),
 	}
+
+	for _, option := range options {
+		option(renderer)
+	}
+
+	return renderer
 }
 
 // NewHTMLTemplateRenderer create a new HTMLTemplateRenderer
-func NewHTMLTemplateRenderer(writer http.ResponseWriter) *HTMLTemplateRenderer {
-	return NewHTMLTemplateRendererWithProvider(writer, defaultProvider)
+func NewHTMLTemplateRenderer(writer http.ResponseWriter, options ...RendererOption) *HTMLTemplateRenderer {
+	mu.RLock()
+	provider := defaultProvider
+	mu.RUnlock()
+
+	return NewHTMLTemplateRendererWithProvider(writer, provider, options...)
 }
 
 // SetHTMLTemplateProvider sets the default repository
@@ -59,7 +59,110 @@ var _ = Describe("HTMLTemplateRenderer", func() {
 		Expect(recorder.Code).To(Equal(http.StatusOK))
 	})
 
-	Context("when template rendering fails", func() {
+	Context("when the renderer has a layout", func() {
+		var options []giraffe.RendererOption
+
+		BeforeEach(func() {
+			templates := template.New("assets").Funcs(giraffe.LayoutFuncs())
+			template.Must(templates.New("layout").Parse(`<title>{{block "title" .}}Giraffe{{end}}</title><body>{{yield}}</body>`))
+			template.Must(templates.New("admin").Parse(`<main>{{yield}}</main>`))
+			template.Must(templates.New("broken").Parse(`<body></body>`))
+			template.Must(templates.New("titled").Parse(`<title>{{yield}}</title>`))
+			template.Must(templates.New("twice").Parse(`<header>{{yield}}</header><main>{{yield}}</main>`))
+			template.Must(templates.New("home").Parse(`Welcome home, {{.}}!`))
+
+			provider.ProvideReturns(templates, nil)
+			options = []giraffe.RendererOption{giraffe.WithLayout("layout")}
+		})
+
+		JustBeforeEach(func() {
+			renderer = giraffe.NewHTMLTemplateRendererWithProvider(responseWriter, provider, options...)
+		})
+
+		It("renders the template within the default layout", func() {
+			Expect(renderer.Render("home", "<Ben>")).To(Succeed())
+			Expect(recorder.Body.String()).To(Equal("<title>Giraffe</title><body>Welcome home, &lt;Ben&gt;!</body>"))
+			Expect(recorder.HeaderMap).To(HaveKeyWithValue("Content-Type", []string{"text/html; charset=UTF-8"}))
+		})
+
+		It("renders the template within given layout", func() {
+			Expect(renderer.RenderWithLayout("admin", "home", "Ben")).To(Succeed())
+			Expect(recorder.Body.String()).To(Equal("<main>Welcome home, Ben!</main>"))
+		})
+
+		It("renders the template within the title of the layout", func() {
+			Expect(renderer.RenderWithLayout("titled", "home", "Ben")).To(Succeed())
+			Expect(recorder.Body.String()).To(Equal("<title>Welcome home, Ben!</title>"))
+		})
+
+		It("renders the template for every yield of the layout", func() {
+			Expect(renderer.RenderWithLayout("twice", "home", "Ben")).To(Succeed())
+			Expect(recorder.Body.String()).To(Equal("<header>Welcome home, Ben!</header><main>Welcome home, Ben!</main>"))
+		})
+
+		It("renders the template without layout", func() {
+			Expect(renderer.RenderWithLayout("", "home", "Ben")).To(Succeed())
+			Expect(recorder.Body.String()).To(Equal("Welcome home, Ben!"))
+		})
+
+		It("returns an error when the layout does not yield", func() {
+			Expect(renderer.RenderWithLayout("broken", "home", "Ben")).To(MatchError(giraffe.ErrLayoutNotYield))
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		})
+
+		It("returns an error when the layout does not exist", func() {
+			Expect(renderer.RenderWithLayout("missing", "home", "Ben")).To(HaveOccurred())
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+			Expect(recorder.Body.String()).NotTo(ContainSubstring("Welcome home"))
+		})
+	})
+
+	It("sets the content length", func() {
+		Expect(renderer.Render("home", "Ben")).To(Succeed())
+		Expect(recorder.HeaderMap).To(HaveKeyWithValue("Content-Length", []string{"19"}))
+	})
+
+	Context("when the template execution fails", func() {
+		BeforeEach(func() {
+			templates := template.New("assets")
+			template.Must(templates.New("home").Parse(`<h1>Welcome home</h1>{{.Name}}`))
+			template.Must(templates.New("500").Parse(`<h1>{{.Title}}</h1>`))
+			provider.ProvideReturns(templates, nil)
+		})
+
+		It("writes clean problem details", func() {
+			Expect(renderer.Render("home", "Ben")).To(HaveOccurred())
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+			Expect(recorder.HeaderMap).To(HaveKeyWithValue("Content-Type", []string{"application/problem+json; charset=UTF-8"}))
+			Expect(recorder.Body.String()).NotTo(ContainSubstring("Welcome home"))
+		})
+
+		Context("when the renderer has an error template", func() {
+			JustBeforeEach(func() {
+				renderer = giraffe.NewHTMLTemplateRendererWithProvider(responseWriter, provider, giraffe.WithRendererErrorTemplate("500"))
+			})
+
+			It("renders the error template", func() {
+				Expect(renderer.Render("home", "Ben")).To(HaveOccurred())
+				Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+				Expect(recorder.HeaderMap).To(HaveKeyWithValue("Content-Type", []string{"text/html; charset=UTF-8"}))
+				Expect(recorder.Body.String()).To(Equal("<h1>Internal Server Error</h1>"))
+			})
+		})
+
+		Context("when the streaming is enabled", func() {
+			JustBeforeEach(func() {
+				renderer = giraffe.NewHTMLTemplateRendererWithProvider(responseWriter, provider, giraffe.WithStreaming(true))
+			})
+
+			It("writes the template directly into the response", func() {
+				Expect(renderer.Render("home", "Ben")).To(HaveOccurred())
+				Expect(recorder.Body.String()).To(HavePrefix("<h1>Welcome home</h1>"))
+			})
+		})
+	})
+
+	Context("when writing the response fails", func() {
 		var fakeResponseWriter *fakes.FakeResponseWriter
 
 		BeforeEach(func() {
@@ -73,9 +176,19 @@ var _ = Describe("HTMLTemplateRenderer", func() {
 			Expect(renderer.Render("home", "Ben")).To(MatchError("Oh no!"))
 		})
 
-		It("has correct status code", func() {
-			renderer.Render("home", "Ben")
-			Expect(fakeResponseWriter.Code()).To(Equal(http.StatusInternalServerError))
+		Context("when the streaming is enabled", func() {
+			JustBeforeEach(func() {
+				renderer = giraffe.NewHTMLTemplateRendererWithProvider(responseWriter, provider, giraffe.WithStreaming(true))
+			})
+
+			It("returns the error", func() {
+				Expect(renderer.Render("home", "Ben")).To(MatchError("Oh no!"))
+			})
+
+			It("has correct status code", func() {
+				renderer.Render("home", "Ben")
+				Expect(fakeResponseWriter.Code()).To(Equal(http.StatusInternalServerError))
+			})
 		})
 	})
 
@@ -92,6 +205,32 @@ var _ = Describe("HTMLTemplateRenderer", func() {
 			renderer.Render("home", "Ben")
 			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
 		})
+
+		It("writes problem details", func() {
+			renderer.Render("home", "Ben")
+			Expect(recorder.HeaderMap).To(HaveKeyWithValue("Content-Type", []string{"application/problem+json; charset=UTF-8"}))
+			Expect(recorder.Body.String()).To(ContainSubstring("Unable to render 'home' html template"))
+		})
+
+		Context("when the renderer has an error handler", func() {
+			var handler *fakes.FakeErrorHandler
+
+			BeforeEach(func() {
+				handler = new(fakes.FakeErrorHandler)
+			})
+
+			JustBeforeEach(func() {
+				renderer = giraffe.NewHTMLTemplateRendererWithProvider(responseWriter, provider, giraffe.WithRendererErrorHandler(handler))
+			})
+
+			It("uses the error handler", func() {
+				renderer.Render("home", "Ben")
+				Expect(handler.HandleErrorCallCount()).To(Equal(1))
+
+				_, _, problem := handler.HandleErrorArgsForCall(0)
+				Expect(problem.Err).To(MatchError("oh no!"))
+			})
+		})
 	})
 })
 
@@ -2,11 +2,13 @@ package giraffe
 
 import (
 	"html/template"
-	"io/ioutil"
-	"os"
-	"path/filepath"
+	"io/fs"
+	"time"
 )
 
+// DefaultPollInterval is the default interval between the checks for changed templates
+const DefaultPollInterval = time.Second
+
 // TemplateCompilation defines the compilcation options
 type TemplateCompilation uint8
 
@@ -15,49 +17,90 @@ const (
 	CompileAlways TemplateCompilation = iota
 	// CompileOnce  enables compilation only once when the templates are used for first time
 	CompileOnce
+	// CompileOnChange enables compilation only when the template files have been
+	// changed since the last compilation. The last successfully compiled templates
+	// are used when the changed templates cannot be compiled.
+	CompileOnChange
 )
 
 // HTMLTemplateRepository represents a template repository
 type HTMLTemplateRepository struct {
-	// templates are compiled HTML templates
-	templates *template.Template
+	// cache of the compiled HTML templates
+	cache templateCache[*template.Template]
 
 	// Directory to load templates. Default is "templates".
 	Directory string
-	// FileExtensions to parse template files from. Defaults to [".tmpl"].
+	// Directories to load templates before Directory. The templates of a later
+	// directory override the templates with the same name of an earlier one,
+	// e.g. a shared theme can be overlaid by the application templates.
+	Directories []string
+	// FileSystem to load the templates from, e.g. embed.FS. The directories are
+	// paths within it. Defaults to the operating system file system.
+	FileSystem fs.FS
+	// FileExtension to parse template files from
 	FileExtension string
+	// FileExtensions to parse template files from in addition to FileExtension.
+	// Defaults to [".tmpl"] when both are empty.
+	FileExtensions []string
 	// Compilation to compile the templates
 	Compilation TemplateCompilation
 	// UtilFuncs is a FuncMap to apply to the template upon compilation. This is useful for helper functions. Defaults to [].
 	UtilFuncs template.FuncMap
+	// PartialsDirectory is a subdirectory of Directory with the partial templates.
+	// The partials are named by their path relative to PartialsDirectory, e.g.
+	// "header" for "partials/header.tmpl". Defaults to none.
+	PartialsDirectory string
+	// PollInterval is the interval between the checks for changed templates when
+	// Compilation is CompileOnChange. Defaults to DefaultPollInterval.
+	PollInterval time.Duration
 }
 
-// Provide returns the repository compiled templates
+// Provide returns the repository compiled templates. The read and parse
+// errors of the template files are returned as TemplateErrors. It is safe
+// for concurrent use.
 func (repository *HTMLTemplateRepository) Provide() (*template.Template, error) {
-	option := repository.Compilation
-	if (option == CompileOnce && repository.templates == nil) || option == CompileAlways {
-		repository.templates = template.New(repository.Directory)
-
-		filepath.Walk(repository.Directory, func(path string, info os.FileInfo, err error) error {
-			if info == nil || info.IsDir() {
-				return nil
-			}
-
-			rel, ext, err := ext(repository.Directory, path)
-			if err != nil {
-				return err
-			}
-
-			if ext != repository.FileExtension {
-				return nil
-			}
-
-			tmpl := repository.templates.New(name(rel, ext))
-			buffer, _ := ioutil.ReadFile(path)
-			tmpl.Funcs(repository.UtilFuncs).Parse(string(buffer))
-			return nil
-		})
-	}
+	return repository.cache.provide(repository.Compilation, repository.PollInterval, repository.compile, repository.source().fingerprint)
+}
+
+// Validate compiles all template files and returns their errors as TemplateErrors.
+// It does not change the provided templates.
+func (repository *HTMLTemplateRepository) Validate() error {
+	_, err := repository.compile()
+	return err
+}
+
+// LastError returns the error of the last compilation of the provided templates.
+// When Compilation is CompileOnChange and the changed templates are invalid,
+// Provide keeps returning the last good templates and LastError returns the
+// errors of the changed ones until they are fixed.
+func (repository *HTMLTemplateRepository) LastError() error {
+	return repository.cache.lastError()
+}
 
-	return repository.templates, nil
+// compile parses the template files into a new set of templates
+func (repository *HTMLTemplateRepository) compile() (*template.Template, error) {
+	templates := template.New(repository.Directory).Funcs(LayoutFuncs())
+
+	errs := repository.source().walk(func(file *templateFile) error {
+		buffer, err := file.read()
+		if err != nil {
+			return err
+		}
+
+		_, err = templates.New(file.name).Funcs(repository.UtilFuncs).Parse(string(buffer))
+		return err
+	})
+
+	return templates, errs.err()
+}
+
+// source returns the template files of the repository
+func (repository *HTMLTemplateRepository) source() *templateSource {
+	return &templateSource{
+		directory:   repository.Directory,
+		directories: repository.Directories,
+		fileSystem:  repository.FileSystem,
+		extensions:  append([]string{repository.FileExtension}, repository.FileExtensions...),
+		partials:    repository.PartialsDirectory,
+	}
 }
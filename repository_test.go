@@ -1,10 +1,15 @@
 package giraffe_test
 
 import (
+	"errors"
 	"html/template"
 	"io/ioutil"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"sync"
+	"testing/fstest"
+	"time"
 
 	"github.com/svett/giraffe"
 
@@ -42,6 +47,18 @@ var _ = Describe("HTMLTemplateRepository", func() {
 		Expect(contentBuffer).To(gbytes.Say("Content of Bible"))
 	})
 
+	Context("when the directory is empty", func() {
+		BeforeEach(func() {
+			repository.Directory = ""
+		})
+
+		It("compiles the templates of the working directory", func() {
+			templates, err := repository.Provide()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(templates.Lookup("assets/home")).NotTo(BeNil())
+		})
+	})
+
 	It("compiles all templates with particular file extension", func() {
 		templates, err := repository.Provide()
 		Expect(err).NotTo(HaveOccurred())
@@ -109,6 +126,139 @@ var _ = Describe("HTMLTemplateRepository", func() {
 		Expect(homeBuffer).To(gbytes.Say("Hello, World!"))
 	})
 
+	Context("when the repository has a file system", func() {
+		BeforeEach(func() {
+			repository.Directory = "views"
+			repository.FileSystem = fstest.MapFS{
+				"views/home.tmpl":        {Data: []byte("Welcome home, {{.}}!")},
+				"views/users/index.tmpl": {Data: []byte("Users of {{.}}")},
+				"views/info.notmpl":      {Data: []byte("Info")},
+				"other/page.tmpl":        {Data: []byte("Page")},
+			}
+		})
+
+		It("compiles the templates of the file system", func() {
+			templates, err := repository.Provide()
+			Expect(err).NotTo(HaveOccurred())
+
+			buffer := gbytes.NewBuffer()
+			Expect(templates.ExecuteTemplate(buffer, "users/index", "John")).To(Succeed())
+			Expect(buffer).To(gbytes.Say("Users of John"))
+
+			Expect(templates.Lookup("home")).NotTo(BeNil())
+			Expect(templates.Lookup("info")).To(BeNil())
+			Expect(templates.Lookup("page")).To(BeNil())
+		})
+
+		It("compiles the templates of the root directory", func() {
+			repository.Directory = ""
+
+			templates, err := repository.Provide()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(templates.Lookup("views/home")).NotTo(BeNil())
+			Expect(templates.Lookup("other/page")).NotTo(BeNil())
+		})
+
+		It("returns the errors with the path of the file system", func() {
+			repository.FileSystem.(fstest.MapFS)["views/broken.tmpl"] = &fstest.MapFile{Data: []byte("{{.")}
+
+			err := repository.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.(giraffe.TemplateErrors)[0].Path).To(Equal("views/broken.tmpl"))
+		})
+
+		It("compiles the templates of a directory file system", func() {
+			repository.Directory = "assets"
+			repository.FileSystem = os.DirFS(".")
+
+			templates, err := repository.Provide()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(templates.Lookup("home")).NotTo(BeNil())
+		})
+	})
+
+	Context("when the repository has multiple directories and extensions", func() {
+		BeforeEach(func() {
+			repository.Directory = "app"
+			repository.Directories = []string{"theme"}
+			repository.FileExtension = ""
+			repository.FileExtensions = []string{".tmpl", ".html", ".gohtml"}
+			repository.FileSystem = fstest.MapFS{
+				"theme/layout.html":  {Data: []byte("Theme layout")},
+				"theme/home.tmpl":    {Data: []byte("Theme home")},
+				"theme/footer.txt":   {Data: []byte("Theme footer")},
+				"app/home.gohtml":    {Data: []byte("App home")},
+				"app/users/new.tmpl": {Data: []byte("New user")},
+			}
+		})
+
+		execute := func(name string) string {
+			templates, err := repository.Provide()
+			Expect(err).NotTo(HaveOccurred())
+
+			buffer := gbytes.NewBuffer()
+			Expect(templates.ExecuteTemplate(buffer, name, nil)).To(Succeed())
+			return string(buffer.Contents())
+		}
+
+		It("compiles the templates with all extensions", func() {
+			Expect(execute("layout")).To(Equal("Theme layout"))
+			Expect(execute("users/new")).To(Equal("New user"))
+
+			templates, err := repository.Provide()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(templates.Lookup("footer")).To(BeNil())
+		})
+
+		It("overrides the templates of earlier directories", func() {
+			Expect(execute("home")).To(Equal("App home"))
+		})
+
+		It("loads the directories in order", func() {
+			repository.Directory = ""
+			repository.Directories = []string{"app", "theme"}
+			Expect(execute("home")).To(Equal("Theme home"))
+		})
+
+		It("compiles the templates with default extension", func() {
+			repository.FileExtensions = nil
+
+			templates, err := repository.Provide()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(templates.Lookup("users/new")).NotTo(BeNil())
+			Expect(templates.Lookup("layout")).To(BeNil())
+		})
+	})
+
+	Context("when the repository has partials and layouts", func() {
+		BeforeEach(func() {
+			var err error
+			repository.Directory, err = ioutil.TempDir("", "templates")
+			Expect(err).NotTo(HaveOccurred())
+			repository.PartialsDirectory = "partials"
+
+			Expect(os.Mkdir(filepath.Join(repository.Directory, "partials"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(repository.Directory, "partials", "header.tmpl"), []byte("<h1>{{.}}</h1>"), 0777)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(repository.Directory, "layout.tmpl"), []byte(`{{template "header" .}}{{yield}}`), 0777)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(repository.Directory, "page.tmpl"), []byte("Index, {{.}}"), 0777)).To(Succeed())
+		})
+
+		It("names the partials relative to the partials directory", func() {
+			templates, err := repository.Provide()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(templates.Lookup("header")).NotTo(BeNil())
+			Expect(templates.Lookup("partials/header")).To(BeNil())
+		})
+
+		It("renders the pages within the layout", func() {
+			recorder := httptest.NewRecorder()
+			renderer := giraffe.NewHTMLTemplateRendererWithProvider(recorder, repository, giraffe.WithLayout("layout"))
+
+			Expect(renderer.Render("page", "John")).To(Succeed())
+			Expect(recorder.Body.String()).To(Equal("<h1>John</h1>Index, John"))
+		})
+	})
+
 	Context("when template compilation is set to 'always'", func() {
 		It("compiles the templates everytime", func() {
 			var (
@@ -139,4 +289,176 @@ var _ = Describe("HTMLTemplateRepository", func() {
 			Expect(buffer).To(gbytes.Say("Welcome, John"))
 		})
 	})
+
+	Context("when template compilation is set to 'on change'", func() {
+		var templatePath string
+
+		BeforeEach(func() {
+			var err error
+
+			repository.Compilation = giraffe.CompileOnChange
+			repository.PollInterval = time.Nanosecond
+			repository.Directory, err = ioutil.TempDir("", "templates")
+			Expect(err).NotTo(HaveOccurred())
+
+			templatePath = filepath.Join(repository.Directory, "page.tmpl")
+			Expect(ioutil.WriteFile(templatePath, []byte("Index, {{.}}"), 0777)).To(Succeed())
+		})
+
+		render := func() string {
+			templates, err := repository.Provide()
+			Expect(err).NotTo(HaveOccurred())
+
+			buffer := gbytes.NewBuffer()
+			Expect(templates.ExecuteTemplate(buffer, "page", "John")).To(Succeed())
+			return string(buffer.Contents())
+		}
+
+		touch := func(content string) {
+			Expect(ioutil.WriteFile(templatePath, []byte(content), 0777)).To(Succeed())
+			modTime := time.Now().Add(time.Minute)
+			Expect(os.Chtimes(templatePath, modTime, modTime)).To(Succeed())
+		}
+
+		It("compiles the templates only when they have been changed", func() {
+			first, err := repository.Provide()
+			Expect(err).NotTo(HaveOccurred())
+
+			second, err := repository.Provide()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(second).To(BeIdenticalTo(first))
+
+			touch("Welcome, {{.}}")
+			Expect(render()).To(Equal("Welcome, John"))
+		})
+
+		It("keeps the last good templates when the changed templates are invalid", func() {
+			Expect(render()).To(Equal("Index, John"))
+
+			Expect(repository.LastError()).NotTo(HaveOccurred())
+
+			touch("Welcome, {{.")
+			Expect(render()).To(Equal("Index, John"))
+
+			var errs giraffe.TemplateErrors
+			Expect(errors.As(repository.LastError(), &errs)).To(BeTrue())
+			Expect(errs[0].Path).To(Equal(templatePath))
+
+			touch("Hello, {{.}}")
+			Expect(render()).To(Equal("Hello, John"))
+			Expect(repository.LastError()).NotTo(HaveOccurred())
+		})
+
+		It("does not check for changes before the poll interval elapses", func() {
+			repository.PollInterval = time.Hour
+			Expect(render()).To(Equal("Index, John"))
+
+			touch("Welcome, {{.}}")
+			Expect(render()).To(Equal("Index, John"))
+		})
+	})
+
+	Context("when the templates are invalid", func() {
+		BeforeEach(func() {
+			var err error
+			repository.Directory, err = ioutil.TempDir("", "templates")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(ioutil.WriteFile(filepath.Join(repository.Directory, "page.tmpl"), []byte("Index, {{.}}"), 0777)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(repository.Directory, "broken.tmpl"), []byte("Line\n{{.Name"), 0777)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(repository.Directory, "unknown.tmpl"), []byte("{{unknown}}"), 0777)).To(Succeed())
+		})
+
+		It("returns the errors of all template files", func() {
+			_, err := repository.Provide()
+			Expect(err).To(HaveOccurred())
+
+			var errs giraffe.TemplateErrors
+			Expect(errors.As(err, &errs)).To(BeTrue())
+			Expect(errs).To(HaveLen(2))
+
+			Expect(errs[0].Path).To(Equal(filepath.Join(repository.Directory, "broken.tmpl")))
+			Expect(errs[0].Line).To(Equal(2))
+			Expect(errs[0].Error()).To(HavePrefix(filepath.Join(repository.Directory, "broken.tmpl") + ":2: "))
+
+			Expect(errs[1].Path).To(Equal(filepath.Join(repository.Directory, "unknown.tmpl")))
+			Expect(errs[1].Err.Error()).To(ContainSubstring(`function "unknown" not defined`))
+		})
+
+		It("validates the templates", func() {
+			Expect(repository.Validate()).To(HaveOccurred())
+
+			repository.Directory = "assets"
+			Expect(repository.Validate()).To(Succeed())
+		})
+	})
+
+	It("returns an error when the directory does not exist", func() {
+		repository.Directory = "missing"
+
+		_, err := repository.Provide()
+		Expect(err).To(MatchError(ContainSubstring("missing")))
+	})
+
+	Describe("concurrent use", func() {
+		provideConcurrently := func() []*template.Template {
+			var (
+				wg     sync.WaitGroup
+				result = make([]*template.Template, 20)
+			)
+
+			for index := range result {
+				wg.Add(1)
+				go func(index int) {
+					defer GinkgoRecover()
+					defer wg.Done()
+
+					templates, err := repository.Provide()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(templates.ExecuteTemplate(ioutil.Discard, "home", "John")).To(Succeed())
+					result[index] = templates
+				}(index)
+			}
+
+			wg.Wait()
+			return result
+		}
+
+		It("compiles the templates once for all concurrent requests", func() {
+			result := provideConcurrently()
+			for _, templates := range result {
+				Expect(templates).To(BeIdenticalTo(result[0]))
+			}
+		})
+
+		It("is safe when the templates are compiled always", func() {
+			repository.Compilation = giraffe.CompileAlways
+			Expect(provideConcurrently()).NotTo(ContainElement(BeNil()))
+		})
+
+		It("is safe when the templates are compiled on change", func() {
+			repository.Compilation = giraffe.CompileOnChange
+			repository.PollInterval = time.Nanosecond
+			Expect(provideConcurrently()).NotTo(ContainElement(BeNil()))
+		})
+
+		It("is safe when the renderers share the repository", func() {
+			giraffe.SetHTMLTemplateProvider(repository)
+			defer giraffe.SetHTMLTemplateProvider(&giraffe.HTMLTemplateRepository{Directory: "templates", FileExtension: ".tmpl", Compilation: giraffe.CompileOnce})
+
+			var wg sync.WaitGroup
+			for index := 0; index < 20; index++ {
+				wg.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+
+					recorder := httptest.NewRecorder()
+					Expect(giraffe.NewHTMLTemplateRenderer(recorder).Render("home", "John")).To(Succeed())
+					Expect(recorder.Body.String()).To(ContainSubstring("Welcome home, John!"))
+				}()
+			}
+			wg.Wait()
+		})
+	})
 })
@@ -0,0 +1,164 @@
+package giraffe
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// TraceParentHeader is the W3C Trace Context header
+const TraceParentHeader = "traceparent"
+
+var requestIDRegexp = regexp.MustCompile(`^[A-Za-z0-9._:\-]{1,128}$`)
+
+type requestIDKey struct{}
+
+// RequestIDOption configures the request ID middleware
+type RequestIDOption func(*requestIDHandler)
+
+// WithRequestIDGenerator sets the func that generates the IDs of the requests without one.
+// Defaults to a random 128-bit hex string.
+func WithRequestIDGenerator(generate func() string) RequestIDOption {
+	return func(handler *requestIDHandler) {
+		handler.generate = generate
+	}
+}
+
+// WithRequestIDHeader sets the header that carries the request ID. Defaults to X-Request-ID.
+func WithRequestIDHeader(header string) RequestIDOption {
+	return func(handler *requestIDHandler) {
+		handler.header = header
+	}
+}
+
+// NewHTTPRequestID propagates the request ID. The ID is read from the request
+// header or the trace ID of the traceparent header. Otherwise a new ID is
+// generated. The ID is stored in the request context and echoed in the
+// response header. It is carried by the response writer as well, so that
+// the preceding HTTP logger and the encoders without request include it.
+func NewHTTPRequestID(options ...RequestIDOption) HandlerFunc {
+	handler := &requestIDHandler{
+		header:   RequestIDHeader,
+		generate: NewRequestID,
+	}
+
+	for _, option := range options {
+		option(handler)
+	}
+
+	return handler.handle
+}
+
+type requestIDHandler struct {
+	header   string
+	generate func() string
+}
+
+func (handler *requestIDHandler) handle(w http.ResponseWriter, request *http.Request, next http.HandlerFunc) {
+	id := request.Header.Get(handler.header)
+	if !requestIDRegexp.MatchString(id) {
+		id = traceID(request.Header.Get(TraceParentHeader))
+	}
+	if id == "" {
+		id = handler.generate()
+	}
+
+	w.Header().Set(handler.header, id)
+
+	// the ID is carried by the writer for the middlewares and encoders that do not see the request context
+	holder := findRequestIDHolder(w)
+	if holder == nil {
+		writer := NewResponseWriter(w)
+		holder, w = writer.(requestIDHolder), writer
+	}
+	holder.setRequestID(id)
+
+	next(w, request.WithContext(WithRequestID(request.Context(), id)))
+}
+
+// requestIDHolder is a response writer that carries the request ID
+type requestIDHolder interface {
+	requestID() string
+	setRequestID(id string)
+}
+
+// findRequestIDHolder returns the first writer of the unwrap chain of w that carries the request ID
+func findRequestIDHolder(w http.ResponseWriter) requestIDHolder {
+	for w != nil {
+		if holder, ok := w.(requestIDHolder); ok {
+			return holder
+		}
+
+		unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return nil
+		}
+		w = unwrapper.Unwrap()
+	}
+	return nil
+}
+
+// responseRequestID returns the request ID carried by the writers of the unwrap chain of w
+func responseRequestID(w http.ResponseWriter) string {
+	for w != nil {
+		if holder, ok := w.(requestIDHolder); ok && holder.requestID() != "" {
+			return holder.requestID()
+		}
+
+		unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return ""
+		}
+		w = unwrapper.Unwrap()
+	}
+	return ""
+}
+
+// NewRequestID generates a random 128-bit hex request ID
+func NewRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(id)
+}
+
+// WithRequestID returns a copy of the context that carries the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID stored in the context
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// traceID returns the trace ID of traceparent header value
+// in format version-traceid-parentid-flags
+func traceID(traceparent string) string {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 || len(parts[1]) != 32 {
+		return ""
+	}
+
+	id := strings.ToLower(parts[1])
+	if _, err := hex.DecodeString(id); err != nil || id == strings.Repeat("0", 32) {
+		return ""
+	}
+	return id
+}
+
+func requestID(request *http.Request) string {
+	if request == nil {
+		return ""
+	}
+	return RequestID(request.Context())
+}
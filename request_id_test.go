@@ -0,0 +1,133 @@
+package giraffe_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/svett/giraffe"
+	"github.com/svett/giraffe/fakes"
+)
+
+var _ = Describe("RequestID", func() {
+	var (
+		handler  giraffe.HandlerFunc
+		request  *http.Request
+		recorder *httptest.ResponseRecorder
+		id       string
+	)
+
+	BeforeEach(func() {
+		handler = giraffe.NewHTTPRequestID(giraffe.WithRequestIDGenerator(func() string {
+			return "generated"
+		}))
+		request = httptest.NewRequest("GET", "http://example.com/users/1", nil)
+		recorder = httptest.NewRecorder()
+		id = ""
+	})
+
+	serve := func() {
+		handler(recorder, request, func(w http.ResponseWriter, req *http.Request) {
+			id = giraffe.RequestID(req.Context())
+		})
+	}
+
+	It("generates a new request ID", func() {
+		serve()
+		Expect(id).To(Equal("generated"))
+		Expect(recorder.Header().Get("X-Request-ID")).To(Equal("generated"))
+	})
+
+	It("propagates the request ID of the request", func() {
+		request.Header.Set("X-Request-ID", "abc-123")
+		serve()
+		Expect(id).To(Equal("abc-123"))
+		Expect(recorder.Header().Get("X-Request-ID")).To(Equal("abc-123"))
+	})
+
+	It("ignores an invalid request ID", func() {
+		request.Header.Set("X-Request-ID", "<script>")
+		serve()
+		Expect(id).To(Equal("generated"))
+	})
+
+	It("uses the trace ID of the traceparent header", func() {
+		request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		serve()
+		Expect(id).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+	})
+
+	It("generates random request IDs by default", func() {
+		handler = giraffe.NewHTTPRequestID()
+		serve()
+		Expect(id).To(MatchRegexp("^[0-9a-f]{32}$"))
+		Expect(giraffe.NewRequestID()).NotTo(Equal(id))
+	})
+
+	It("returns an empty ID when the context has none", func() {
+		Expect(giraffe.RequestID(context.Background())).To(BeEmpty())
+	})
+
+	It("is logged by the HTTP logger", func() {
+		logger := new(fakes.FakeLogger)
+		chain := giraffe.NewChain(
+			giraffe.NewHTTPLogger(logger, false, giraffe.WithFormatter(giraffe.NewPatternFormatter("%{X-Request-ID}o"))),
+			handler,
+		)
+
+		chain.ThenFunc(func(w http.ResponseWriter, req *http.Request) {}).ServeHTTP(recorder, request)
+		Expect(logger.PrintlnArgsForCall(0)[0]).To(Equal("generated"))
+	})
+
+	It("is included in the error responses", func() {
+		handler(recorder, request, func(w http.ResponseWriter, req *http.Request) {
+			giraffe.NewHTTPEncoder(w, giraffe.WithRequest(req)).EncodeJSONP("alert(1)", "root")
+		})
+
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(recorder.Body.String()).To(ContainSubstring(`"request_id":"generated"`))
+	})
+
+	It("is included in the error responses when the encoder has no request", func() {
+		handler(recorder, request, func(w http.ResponseWriter, req *http.Request) {
+			giraffe.NewHTTPEncoder(w).EncodeJSONP("alert(1)", "root")
+		})
+
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(recorder.Body.String()).To(ContainSubstring(`"request_id":"generated"`))
+	})
+
+	Context("when the header is configured", func() {
+		BeforeEach(func() {
+			handler = giraffe.NewHTTPRequestID(giraffe.WithRequestIDHeader("X-Correlation-ID"), giraffe.WithRequestIDGenerator(func() string {
+				return "generated"
+			}))
+		})
+
+		It("is included in the error responses when the encoder has no request", func() {
+			handler(recorder, request, func(w http.ResponseWriter, req *http.Request) {
+				giraffe.NewHTTPEncoder(w).EncodeJSONP("alert(1)", "root")
+			})
+
+			Expect(recorder.Header().Get("X-Correlation-ID")).To(Equal("generated"))
+			Expect(recorder.Body.String()).To(ContainSubstring(`"request_id":"generated"`))
+		})
+
+		It("is logged by the HTTP logger that precedes it", func() {
+			request.Header.Set("Accept-Encoding", "gzip")
+
+			logger := new(fakes.FakeLogger)
+			chain := giraffe.NewChain(
+				giraffe.NewHTTPLogger(logger, false, giraffe.WithFormatter(&giraffe.JSONFormatter{})),
+				giraffe.NewHTTPCompressor(),
+				handler,
+			)
+
+			chain.ThenFunc(func(w http.ResponseWriter, req *http.Request) {}).ServeHTTP(recorder, request)
+			Expect(logger.PrintlnArgsForCall(0)[0]).To(ContainSubstring(`"request_id":"generated"`))
+		})
+	})
+})
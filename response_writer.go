@@ -0,0 +1,326 @@
+package giraffe
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ResponseWriter is a http.ResponseWriter that tracks the written response.
+// It implements http.Flusher, http.Hijacker, http.CloseNotifier and
+// http.Pusher only when the wrapped writer implements them.
+type ResponseWriter interface {
+	http.ResponseWriter
+	io.ReaderFrom
+
+	// Status returns the status code of the response. It is http.StatusOK
+	// until the response header has been written.
+	Status() int
+	// Size returns the number of bytes written into the response body
+	Size() int64
+	// Written returns true when the response header has been written
+	Written() bool
+	// Start returns the time when the writer was created
+	Start() time.Time
+	// TimeToFirstByte returns the time from Start until the response header has been written
+	TimeToFirstByte() time.Duration
+	// Unwrap returns the wrapped http.ResponseWriter
+	Unwrap() http.ResponseWriter
+}
+
+// NewResponseWriter creates a new ResponseWriter that wraps w
+func NewResponseWriter(w http.ResponseWriter) ResponseWriter {
+	rw := &responseWriter{
+		ResponseWriter: w,
+		start:          time.Now(),
+	}
+
+	f, h, c, p := optionalFlusher{rw}, optionalHijacker{rw}, optionalCloseNotifier{rw}, optionalPusher{rw}
+
+	switch capabilities(w) {
+	case canFlush:
+		return struct {
+			*responseWriter
+			optionalFlusher
+		}{rw, f}
+	case canHijack:
+		return struct {
+			*responseWriter
+			optionalHijacker
+		}{rw, h}
+	case canFlush | canHijack:
+		return struct {
+			*responseWriter
+			optionalFlusher
+			optionalHijacker
+		}{rw, f, h}
+	case canCloseNotify:
+		return struct {
+			*responseWriter
+			optionalCloseNotifier
+		}{rw, c}
+	case canFlush | canCloseNotify:
+		return struct {
+			*responseWriter
+			optionalFlusher
+			optionalCloseNotifier
+		}{rw, f, c}
+	case canHijack | canCloseNotify:
+		return struct {
+			*responseWriter
+			optionalHijacker
+			optionalCloseNotifier
+		}{rw, h, c}
+	case canFlush | canHijack | canCloseNotify:
+		return struct {
+			*responseWriter
+			optionalFlusher
+			optionalHijacker
+			optionalCloseNotifier
+		}{rw, f, h, c}
+	case canPush:
+		return struct {
+			*responseWriter
+			optionalPusher
+		}{rw, p}
+	case canFlush | canPush:
+		return struct {
+			*responseWriter
+			optionalFlusher
+			optionalPusher
+		}{rw, f, p}
+	case canHijack | canPush:
+		return struct {
+			*responseWriter
+			optionalHijacker
+			optionalPusher
+		}{rw, h, p}
+	case canFlush | canHijack | canPush:
+		return struct {
+			*responseWriter
+			optionalFlusher
+			optionalHijacker
+			optionalPusher
+		}{rw, f, h, p}
+	case canCloseNotify | canPush:
+		return struct {
+			*responseWriter
+			optionalCloseNotifier
+			optionalPusher
+		}{rw, c, p}
+	case canFlush | canCloseNotify | canPush:
+		return struct {
+			*responseWriter
+			optionalFlusher
+			optionalCloseNotifier
+			optionalPusher
+		}{rw, f, c, p}
+	case canHijack | canCloseNotify | canPush:
+		return struct {
+			*responseWriter
+			optionalHijacker
+			optionalCloseNotifier
+			optionalPusher
+		}{rw, h, c, p}
+	case canFlush | canHijack | canCloseNotify | canPush:
+		return struct {
+			*responseWriter
+			optionalFlusher
+			optionalHijacker
+			optionalCloseNotifier
+			optionalPusher
+		}{rw, f, h, c, p}
+	default:
+		return rw
+	}
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	status    int
+	size      int64
+	start     time.Time
+	firstByte time.Time
+	id        string
+}
+
+func (w *responseWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *responseWriter) Size() int64 {
+	return w.size
+}
+
+func (w *responseWriter) Written() bool {
+	return w.status != 0
+}
+
+func (w *responseWriter) Start() time.Time {
+	return w.start
+}
+
+func (w *responseWriter) TimeToFirstByte() time.Duration {
+	if w.firstByte.IsZero() {
+		return 0
+	}
+	return w.firstByte.Sub(w.start)
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *responseWriter) requestID() string {
+	return w.id
+}
+
+func (w *responseWriter) setRequestID(id string) {
+	w.id = id
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.Written() {
+		return
+	}
+
+	if code >= http.StatusOK {
+		w.status = code
+		w.firstByte = time.Now()
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	if !w.Written() {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	n, err := w.ResponseWriter.Write(data)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *responseWriter) ReadFrom(reader io.Reader) (int64, error) {
+	if !w.Written() {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	var (
+		n   int64
+		err error
+	)
+
+	if readerFrom, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = readerFrom.ReadFrom(reader)
+	} else {
+		n, err = io.Copy(writerOnly{w.ResponseWriter}, reader)
+	}
+
+	w.size += n
+	return n, err
+}
+
+func (w *responseWriter) flush() {
+	if !w.Written() {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	w.ResponseWriter.(http.Flusher).Flush()
+}
+
+func (w *responseWriter) hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := w.ResponseWriter.(http.Hijacker).Hijack()
+	if err == nil && !w.Written() {
+		w.status = http.StatusSwitchingProtocols
+		w.firstByte = time.Now()
+	}
+	return conn, rw, err
+}
+
+func (w *responseWriter) closeNotify() <-chan bool {
+	return w.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
+
+func (w *responseWriter) push(target string, opts *http.PushOptions) error {
+	return w.ResponseWriter.(http.Pusher).Push(target, opts)
+}
+
+// writerOnly hides the io.ReaderFrom of the writer to avoid recursion in io.Copy
+type writerOnly struct {
+	io.Writer
+}
+
+const (
+	canFlush = 1 << iota
+	canHijack
+	canCloseNotify
+	canPush
+)
+
+// capabilities returns the optional interfaces that are implemented by w
+func capabilities(w http.ResponseWriter) int {
+	var capability int
+	if _, ok := w.(http.Flusher); ok {
+		capability |= canFlush
+	}
+	if _, ok := w.(http.Hijacker); ok {
+		capability |= canHijack
+	}
+	if _, ok := w.(http.CloseNotifier); ok {
+		capability |= canCloseNotify
+	}
+	if _, ok := w.(http.Pusher); ok {
+		capability |= canPush
+	}
+	return capability
+}
+
+// optionalWriter implements the optional interfaces on behalf of a wrapping writer
+type optionalWriter interface {
+	flush()
+	hijack() (net.Conn, *bufio.ReadWriter, error)
+	closeNotify() <-chan bool
+	push(target string, opts *http.PushOptions) error
+}
+
+// optionalFlusher exposes http.Flusher of an optionalWriter
+type optionalFlusher struct {
+	w optionalWriter
+}
+
+func (f optionalFlusher) Flush() {
+	f.w.flush()
+}
+
+// optionalHijacker exposes http.Hijacker of an optionalWriter
+type optionalHijacker struct {
+	w optionalWriter
+}
+
+func (h optionalHijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return h.w.hijack()
+}
+
+// optionalCloseNotifier exposes http.CloseNotifier of an optionalWriter
+type optionalCloseNotifier struct {
+	w optionalWriter
+}
+
+func (c optionalCloseNotifier) CloseNotify() <-chan bool {
+	return c.w.closeNotify()
+}
+
+// optionalPusher exposes http.Pusher of an optionalWriter
+type optionalPusher struct {
+	w optionalWriter
+}
+
+func (p optionalPusher) Push(target string, opts *http.PushOptions) error {
+	return p.w.push(target, opts)
+}
@@ -0,0 +1,126 @@
+package giraffe_test
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/svett/giraffe"
+	"github.com/svett/giraffe/fakes"
+)
+
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (w *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	return nil, nil, nil
+}
+
+var _ = Describe("ResponseWriter", func() {
+	var (
+		writer   giraffe.ResponseWriter
+		recorder *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		recorder = httptest.NewRecorder()
+		writer = giraffe.NewResponseWriter(recorder)
+	})
+
+	It("has status OK before the header is written", func() {
+		Expect(writer.Written()).To(BeFalse())
+		Expect(writer.Status()).To(Equal(http.StatusOK))
+		Expect(writer.TimeToFirstByte()).To(BeZero())
+	})
+
+	It("tracks the status code", func() {
+		writer.WriteHeader(http.StatusCreated)
+		Expect(writer.Written()).To(BeTrue())
+		Expect(writer.Status()).To(Equal(http.StatusCreated))
+		Expect(recorder.Code).To(Equal(http.StatusCreated))
+	})
+
+	It("tracks the implicit status code", func() {
+		Expect(giraffe.NewHTTPEncoder(writer).EncodeText("hello")).To(Succeed())
+		Expect(writer.Written()).To(BeTrue())
+		Expect(writer.Status()).To(Equal(http.StatusOK))
+	})
+
+	It("tracks the number of written bytes", func() {
+		writer.Write([]byte("hello"))
+		writer.Write([]byte(" world"))
+		Expect(writer.Size()).To(Equal(int64(11)))
+	})
+
+	It("tracks the time to first byte", func() {
+		time.Sleep(time.Millisecond)
+		writer.Write([]byte("hello"))
+		Expect(writer.TimeToFirstByte()).To(BeNumerically(">=", time.Millisecond))
+		Expect(writer.Start()).To(BeTemporally("<", time.Now()))
+	})
+
+	It("reads from a reader", func() {
+		n, err := io.Copy(writer, strings.NewReader("hello"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(int64(5)))
+		Expect(writer.Size()).To(Equal(int64(5)))
+		Expect(recorder.Body.String()).To(Equal("hello"))
+	})
+
+	It("flushes the wrapped writer", func() {
+		flusher, ok := writer.(http.Flusher)
+		Expect(ok).To(BeTrue())
+
+		flusher.Flush()
+		Expect(recorder.Flushed).To(BeTrue())
+		Expect(writer.Written()).To(BeTrue())
+	})
+
+	It("hijacks the connection of the wrapped writer", func() {
+		hijacker := &hijackRecorder{ResponseRecorder: recorder}
+		writer = giraffe.NewResponseWriter(hijacker)
+
+		wrapper, ok := writer.(http.Hijacker)
+		Expect(ok).To(BeTrue())
+
+		_, _, err := wrapper.Hijack()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hijacker.hijacked).To(BeTrue())
+		Expect(writer.Status()).To(Equal(http.StatusSwitchingProtocols))
+	})
+
+	It("unwraps the wrapped writer", func() {
+		Expect(writer.Unwrap()).To(Equal(recorder))
+	})
+
+	Context("when the wrapped writer does not support the optional interfaces", func() {
+		BeforeEach(func() {
+			writer = giraffe.NewResponseWriter(fakes.NewFakeResponseWriter(&strings.Builder{}))
+		})
+
+		It("does not implement them", func() {
+			_, ok := writer.(http.Flusher)
+			Expect(ok).To(BeFalse())
+			_, ok = writer.(http.Hijacker)
+			Expect(ok).To(BeFalse())
+			_, ok = writer.(http.CloseNotifier)
+			Expect(ok).To(BeFalse())
+			_, ok = writer.(http.Pusher)
+			Expect(ok).To(BeFalse())
+		})
+
+		It("still unwraps to the wrapped writer for http.ResponseController", func() {
+			Expect(http.NewResponseController(writer).Flush()).To(MatchError(http.ErrNotSupported))
+		})
+	})
+})
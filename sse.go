@@ -0,0 +1,200 @@
+package giraffe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultHeartbeatInterval is the interval of the heartbeats when a non-positive interval is given
+const DefaultHeartbeatInterval = 15 * time.Second
+
+// ErrStreamingUnsupported is returned when the response writer cannot be flushed
+var ErrStreamingUnsupported = errors.New("Streaming is not supported by the response writer")
+
+var eventFieldReplacer = strings.NewReplacer("\r", "", "\n", "")
+
+// eventLineReplacer normalises the line endings. CRLF, CR and LF end a line of an event stream.
+var eventLineReplacer = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// Event is a server-sent event
+type Event struct {
+	// ID sets the last event ID of the client
+	ID string
+	// Name of the event. Clients receive it as message when it is empty.
+	Name string
+	// Retry sets the reconnection time of the client
+	Retry time.Duration
+	// Data of the event. Strings and byte arrays are sent as they are, other models are encoded as JSON.
+	Data Model
+}
+
+// EventStream writes server-sent events
+type EventStream struct {
+	mu      sync.Mutex
+	ctx     context.Context
+	encoder *HTTPEncoder
+	writer  http.ResponseWriter
+	flusher http.Flusher
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// EncodeEventStream starts a server-sent events stream that ends when the context is cancelled.
+// The context is usually the request context that is cancelled when the client disconnects.
+func (enc *HTTPEncoder) EncodeEventStream(ctx context.Context) (*EventStream, error) {
+	flusher, ok := enc.writer.(http.Flusher)
+	if !ok {
+		enc.fail(http.StatusInternalServerError, ErrStreamingUnsupported.Error(), ErrStreamingUnsupported)
+		return nil, ErrStreamingUnsupported
+	}
+
+	header := enc.writer.Header()
+	header.Set(ContentType, ContentEventStream)
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+
+	enc.writer.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	return &EventStream{
+		ctx:     ctx,
+		encoder: enc,
+		writer:  enc.writer,
+		flusher: flusher,
+	}, nil
+}
+
+// Send sends an event to the client
+func (stream *EventStream) Send(event *Event) error {
+	buffer := &bytes.Buffer{}
+
+	if event.ID != "" {
+		fmt.Fprintf(buffer, "id: %s\n", eventFieldReplacer.Replace(event.ID))
+	}
+	if event.Name != "" {
+		fmt.Fprintf(buffer, "event: %s\n", eventFieldReplacer.Replace(event.Name))
+	}
+	if event.Retry > 0 {
+		fmt.Fprintf(buffer, "retry: %d\n", event.Retry/time.Millisecond)
+	}
+
+	data, err := stream.data(event.Data)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range strings.Split(eventLineReplacer.Replace(data), "\n") {
+		fmt.Fprintf(buffer, "data: %s\n", line)
+	}
+	buffer.WriteString("\n")
+
+	return stream.write(buffer.Bytes())
+}
+
+// Comment sends a comment that is ignored by the client
+func (stream *EventStream) Comment(comment string) error {
+	buffer := &bytes.Buffer{}
+	for _, line := range strings.Split(eventLineReplacer.Replace(comment), "\n") {
+		fmt.Fprintf(buffer, ": %s\n", line)
+	}
+	buffer.WriteString("\n")
+
+	return stream.write(buffer.Bytes())
+}
+
+// Heartbeat sends a heartbeat comment every interval to keep the connection alive.
+// It stops when the stream is closed or the context is cancelled. A non-positive
+// interval defaults to DefaultHeartbeatInterval.
+func (stream *EventStream) Heartbeat(interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultHeartbeatInterval
+	}
+
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+
+	if stream.stop != nil {
+		return
+	}
+
+	stream.stop = make(chan struct{})
+	stream.done = make(chan struct{})
+
+	go func(stop, done chan struct{}) {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-stream.ctx.Done():
+				return
+			case <-ticker.C:
+				if err := stream.Comment("heartbeat"); err != nil {
+					return
+				}
+			}
+		}
+	}(stream.stop, stream.done)
+}
+
+// Done returns a channel that is closed when the client disconnects
+func (stream *EventStream) Done() <-chan struct{} {
+	return stream.ctx.Done()
+}
+
+// Close stops the heartbeat. It should be called before the handler returns.
+func (stream *EventStream) Close() {
+	stream.mu.Lock()
+	stop, done := stream.stop, stream.done
+	stream.stop = nil
+	stream.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+func (stream *EventStream) write(data []byte) error {
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+
+	if err := stream.ctx.Err(); err != nil {
+		return err
+	}
+
+	if _, err := stream.writer.Write(data); err != nil {
+		return err
+	}
+
+	stream.flusher.Flush()
+	return nil
+}
+
+func (stream *EventStream) data(model Model) (string, error) {
+	switch data := model.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return data, nil
+	case []byte:
+		return string(data), nil
+	default:
+		buffer, err := stream.encoder.marshalJSON(model, false)
+		if err != nil {
+			return "", err
+		}
+		return string(buffer), nil
+	}
+}
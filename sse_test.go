@@ -0,0 +1,131 @@
+package giraffe_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/svett/giraffe"
+	"github.com/svett/giraffe/fakes"
+)
+
+var _ = Describe("EventStream", func() {
+	var (
+		stream   *giraffe.EventStream
+		recorder *flushRecorder
+		ctx      context.Context
+		cancel   context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		recorder = &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+		ctx, cancel = context.WithCancel(context.Background())
+
+		var err error
+		stream, err = giraffe.NewHTTPEncoder(recorder).EncodeEventStream(ctx)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		stream.Close()
+		cancel()
+	})
+
+	It("has the corrent headers", func() {
+		Expect(recorder.HeaderMap).To(HaveKeyWithValue("Content-Type", []string{"text/event-stream"}))
+		Expect(recorder.HeaderMap).To(HaveKeyWithValue("Cache-Control", []string{"no-cache"}))
+		Expect(recorder.HeaderMap).To(HaveKeyWithValue("X-Accel-Buffering", []string{"no"}))
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.flushes).To(Equal(1))
+	})
+
+	It("sends an event with all fields", func() {
+		Expect(stream.Send(&giraffe.Event{
+			ID:    "42",
+			Name:  "update",
+			Retry: 3 * time.Second,
+			Data:  "first\nsecond",
+		})).To(Succeed())
+
+		Expect(recorder.Body.String()).To(Equal("id: 42\nevent: update\nretry: 3000\ndata: first\ndata: second\n\n"))
+		Expect(recorder.flushes).To(Equal(2))
+	})
+
+	It("encodes the event data as JSON", func() {
+		Expect(stream.Send(&giraffe.Event{Data: map[string]string{"name": "root"}})).To(Succeed())
+		Expect(recorder.Body.String()).To(Equal("data: {\"name\":\"root\"}\n\n"))
+	})
+
+	It("splits the event data on every line ending", func() {
+		Expect(stream.Send(&giraffe.Event{Data: "hello\revent: evil\r\nfirst\nsecond"})).To(Succeed())
+		Expect(recorder.Body.String()).To(Equal("data: hello\ndata: event: evil\ndata: first\ndata: second\n\n"))
+	})
+
+	It("sends a comment", func() {
+		Expect(stream.Comment("ping")).To(Succeed())
+		Expect(recorder.Body.String()).To(Equal(": ping\n\n"))
+	})
+
+	It("splits the comment on every line ending", func() {
+		Expect(stream.Comment("ping\revent: evil\r\npong")).To(Succeed())
+		Expect(recorder.Body.String()).To(Equal(": ping\n: event: evil\n: pong\n\n"))
+	})
+
+	It("sends heartbeats", func() {
+		stream.Heartbeat(time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+		stream.Close()
+
+		Expect(recorder.Body.String()).To(HavePrefix(": heartbeat\n\n"))
+	})
+
+	It("does not panic when the heartbeat interval is not positive", func() {
+		stream.Heartbeat(0)
+		stream.Close()
+
+		Expect(recorder.Body.String()).To(BeEmpty())
+	})
+
+	Context("when the client disconnects", func() {
+		BeforeEach(func() {
+			cancel()
+		})
+
+		It("stops sending events", func() {
+			Expect(stream.Send(&giraffe.Event{Data: "hello"})).To(MatchError(context.Canceled))
+			Expect(recorder.Body.Len()).To(BeZero())
+			Eventually(stream.Done()).Should(BeClosed())
+		})
+	})
+})
+
+var _ = Describe("EncodeEventStream", func() {
+	Context("when the response writer cannot be flushed", func() {
+		It("returns an error", func() {
+			writer := fakes.NewFakeResponseWriter(fakes.FuncWriter(func(data []byte) (int, error) {
+				return len(data), nil
+			}))
+			stream, err := giraffe.NewHTTPEncoder(writer).EncodeEventStream(context.Background())
+			Expect(err).To(MatchError(giraffe.ErrStreamingUnsupported))
+			Expect(stream).To(BeNil())
+			Expect(writer.Code()).To(Equal(http.StatusInternalServerError))
+		})
+
+		It("returns an error behind the HTTP logger", func() {
+			writer := fakes.NewFakeResponseWriter(fakes.FuncWriter(func(data []byte) (int, error) {
+				return len(data), nil
+			}))
+			request := httptest.NewRequest("GET", "http://example.com/events", nil)
+
+			var err error
+			giraffe.NewHTTPLogger(new(fakes.FakeLogger), false)(writer, request, func(w http.ResponseWriter, r *http.Request) {
+				_, err = giraffe.NewHTTPEncoder(w).EncodeEventStream(context.Background())
+			})
+			Expect(err).To(MatchError(giraffe.ErrStreamingUnsupported))
+		})
+	})
+})
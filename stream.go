@@ -0,0 +1,88 @@
+package giraffe
+
+import (
+	"context"
+	"net/http"
+)
+
+// ModelIterator iterates over a sequence of models
+type ModelIterator interface {
+	// Next returns the next model or false when the sequence is exhausted
+	Next() (Model, bool)
+}
+
+// ModelIteratorFunc converts a func into ModelIterator
+type ModelIteratorFunc func() (Model, bool)
+
+// Next returns the next model
+func (f ModelIteratorFunc) Next() (Model, bool) {
+	return f()
+}
+
+// EncodeNDJSON streams the models received from a channel as newline delimited json.
+// The response is flushed after every flushEvery records. The encoding stops
+// when the channel is closed or the context is cancelled.
+func (enc *HTTPEncoder) EncodeNDJSON(ctx context.Context, models <-chan Model, flushEvery int) error {
+	return enc.encodeNDJSON(func() (Model, bool, error) {
+		select {
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		case model, ok := <-models:
+			return model, ok, nil
+		}
+	}, flushEvery)
+}
+
+// EncodeNDJSONIterator streams the models of an iterator as newline delimited json.
+// The response is flushed after every flushEvery records. The encoding stops
+// when the iterator is exhausted or the context is cancelled.
+func (enc *HTTPEncoder) EncodeNDJSONIterator(ctx context.Context, iterator ModelIterator, flushEvery int) error {
+	return enc.encodeNDJSON(func() (Model, bool, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, false, err
+		}
+		model, ok := iterator.Next()
+		return model, ok, nil
+	}, flushEvery)
+}
+
+func (enc *HTTPEncoder) encodeNDJSON(next func() (Model, bool, error), flushEvery int) error {
+	setContentType(enc.writer, ContentNDJSON)
+
+	if flushEvery < 1 {
+		flushEvery = 1
+	}
+
+	flusher, _ := enc.writer.(http.Flusher)
+
+	for count := 0; ; count++ {
+		model, ok, err := next()
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			break
+		}
+
+		data, err := enc.marshalJSON(model, false)
+		if err == nil {
+			_, err = enc.writer.Write(append(data, '\n'))
+		}
+		if err != nil {
+			if count == 0 {
+				enc.fail(http.StatusInternalServerError, "Unable to encode the model as JSON data", err)
+			}
+			return err
+		}
+
+		if flusher != nil && (count+1)%flushEvery == 0 {
+			flusher.Flush()
+		}
+	}
+
+	if flusher != nil {
+		flusher.Flush()
+	}
+	return nil
+}
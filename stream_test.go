@@ -0,0 +1,124 @@
+package giraffe_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/svett/giraffe"
+	"github.com/svett/giraffe/fakes"
+)
+
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushes int
+}
+
+func (w *flushRecorder) Flush() {
+	w.flushes++
+	w.ResponseRecorder.Flush()
+}
+
+var _ = Describe("NDJSON", func() {
+	var (
+		encoder  *giraffe.HTTPEncoder
+		recorder *flushRecorder
+		ctx      context.Context
+		cancel   context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		recorder = &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+		encoder = giraffe.NewHTTPEncoder(recorder)
+		ctx, cancel = context.WithCancel(context.Background())
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	Describe("EncodeNDJSON", func() {
+		var models chan giraffe.Model
+
+		BeforeEach(func() {
+			models = make(chan giraffe.Model, 3)
+			models <- map[string]int{"id": 1}
+			models <- map[string]int{"id": 2}
+			models <- map[string]int{"id": 3}
+			close(models)
+		})
+
+		It("encodes every model on a separate line", func() {
+			Expect(encoder.EncodeNDJSON(ctx, models, 1)).To(Succeed())
+			Expect(recorder.Body.String()).To(Equal("{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n"))
+		})
+
+		It("has the corrent content type", func() {
+			Expect(encoder.EncodeNDJSON(ctx, models, 1)).To(Succeed())
+			Expect(recorder.HeaderMap).To(HaveKeyWithValue("Content-Type", []string{"application/x-ndjson; charset=UTF-8"}))
+		})
+
+		It("flushes the response every N records", func() {
+			Expect(encoder.EncodeNDJSON(ctx, models, 2)).To(Succeed())
+			Expect(recorder.flushes).To(Equal(2))
+		})
+
+		Context("when the context is cancelled", func() {
+			It("stops the encoding", func() {
+				pending := make(chan giraffe.Model)
+				cancel()
+				Expect(encoder.EncodeNDJSON(ctx, pending, 1)).To(MatchError(context.Canceled))
+				Expect(recorder.Body.Len()).To(BeZero())
+			})
+		})
+	})
+
+	Describe("EncodeNDJSONIterator", func() {
+		var iterator giraffe.ModelIterator
+
+		BeforeEach(func() {
+			index := 0
+			iterator = giraffe.ModelIteratorFunc(func() (giraffe.Model, bool) {
+				if index == 2 {
+					return nil, false
+				}
+				index++
+				return index, true
+			})
+		})
+
+		It("encodes every model on a separate line", func() {
+			Expect(encoder.EncodeNDJSONIterator(ctx, iterator, 10)).To(Succeed())
+			Expect(recorder.Body.String()).To(Equal("1\n2\n"))
+			Expect(recorder.flushes).To(Equal(1))
+		})
+
+		Context("when the context is cancelled", func() {
+			It("stops the encoding", func() {
+				cancel()
+				Expect(encoder.EncodeNDJSONIterator(ctx, iterator, 1)).To(MatchError(context.Canceled))
+				Expect(recorder.Body.Len()).To(BeZero())
+			})
+		})
+	})
+
+	Context("when encoding fails", func() {
+		It("has correct status code", func() {
+			fakeResponseWriter := fakes.NewFakeResponseWriter(fakes.FuncWriter(func(_ []byte) (int, error) {
+				return -1, fmt.Errorf("Oh no!")
+			}))
+			encoder = giraffe.NewHTTPEncoder(fakeResponseWriter)
+
+			models := make(chan giraffe.Model, 1)
+			models <- "root"
+			close(models)
+
+			Expect(encoder.EncodeNDJSON(ctx, models, 1)).To(MatchError("Oh no!"))
+			Expect(fakeResponseWriter.Code()).To(Equal(http.StatusInternalServerError))
+		})
+	})
+})
@@ -0,0 +1,120 @@
+package giraffe
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// templateSnapshot is an immutable result of a compilation
+type templateSnapshot[T any] struct {
+	templates T
+	err       error
+	// stale is the error of the changed templates when the last good templates are kept
+	stale     error
+	signature uint64
+	checked   time.Time
+}
+
+// templateCompilation is a compilation in flight
+type templateCompilation[T any] struct {
+	done     chan struct{}
+	snapshot *templateSnapshot[T]
+}
+
+// templateCache caches the compiled templates. It is safe for concurrent use.
+// The concurrent callers share a single compilation in flight and the
+// compiled templates are swapped atomically.
+type templateCache[T any] struct {
+	mu       sync.Mutex
+	inflight *templateCompilation[T]
+	snapshot atomic.Pointer[templateSnapshot[T]]
+}
+
+// provide returns the cached templates or compiles them according to the compilation option.
+// fingerprint is used only when the option is CompileOnChange.
+func (cache *templateCache[T]) provide(option TemplateCompilation, interval time.Duration, compile func() (T, error), fingerprint func() uint64) (T, error) {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	fresh := func(snapshot *templateSnapshot[T]) bool {
+		switch option {
+		case CompileOnce:
+			return snapshot != nil
+		case CompileOnChange:
+			return snapshot != nil && time.Since(snapshot.checked) < interval
+		default:
+			return false
+		}
+	}
+
+	if snapshot := cache.snapshot.Load(); fresh(snapshot) {
+		return snapshot.templates, snapshot.err
+	}
+
+	snapshot := cache.do(func(previous *templateSnapshot[T]) *templateSnapshot[T] {
+		// the templates may have been compiled while waiting for the lock
+		if option != CompileAlways && fresh(previous) {
+			return previous
+		}
+
+		now := time.Now()
+		if option != CompileOnChange {
+			templates, err := compile()
+			return &templateSnapshot[T]{templates: templates, err: err, checked: now}
+		}
+
+		signature := fingerprint()
+		if previous != nil && signature == previous.signature {
+			return &templateSnapshot[T]{templates: previous.templates, err: previous.err, stale: previous.stale, signature: signature, checked: now}
+		}
+
+		templates, err := compile()
+		if err != nil && previous != nil && previous.err == nil {
+			// keep the last good templates when the changed templates are invalid
+			return &templateSnapshot[T]{templates: previous.templates, stale: err, signature: signature, checked: now}
+		}
+
+		return &templateSnapshot[T]{templates: templates, err: err, signature: signature, checked: now}
+	})
+
+	return snapshot.templates, snapshot.err
+}
+
+// lastError returns the error of the last compilation
+func (cache *templateCache[T]) lastError() error {
+	snapshot := cache.snapshot.Load()
+	if snapshot == nil {
+		return nil
+	}
+	if snapshot.stale != nil {
+		return snapshot.stale
+	}
+	return snapshot.err
+}
+
+// do runs fn once for all concurrent callers and stores its snapshot
+func (cache *templateCache[T]) do(fn func(previous *templateSnapshot[T]) *templateSnapshot[T]) *templateSnapshot[T] {
+	cache.mu.Lock()
+	if inflight := cache.inflight; inflight != nil {
+		cache.mu.Unlock()
+		<-inflight.done
+		return inflight.snapshot
+	}
+
+	inflight := &templateCompilation[T]{done: make(chan struct{})}
+	cache.inflight = inflight
+	cache.mu.Unlock()
+
+	defer func() {
+		cache.mu.Lock()
+		cache.inflight = nil
+		cache.mu.Unlock()
+		close(inflight.done)
+	}()
+
+	inflight.snapshot = fn(cache.snapshot.Load())
+	cache.snapshot.Store(inflight.snapshot)
+	return inflight.snapshot
+}
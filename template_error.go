@@ -0,0 +1,71 @@
+package giraffe
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var templateLineRegexp = regexp.MustCompile(`^template: .*?:(\d+):`)
+
+// TemplateError is an error of a template file
+type TemplateError struct {
+	// Path of the template file
+	Path string
+	// Line of the error. It is 0 when the line is unknown.
+	Line int
+	// Err is the read or parse error
+	Err error
+}
+
+func newTemplateError(path string, err error) *TemplateError {
+	templateErr := &TemplateError{Path: path, Err: err}
+
+	if match := templateLineRegexp.FindStringSubmatch(err.Error()); match != nil {
+		templateErr.Line, _ = strconv.Atoi(match[1])
+	}
+
+	return templateErr
+}
+
+// Error returns the error message prefixed with the path and line
+func (err *TemplateError) Error() string {
+	if err.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", err.Path, err.Line, err.Err)
+	}
+	return fmt.Sprintf("%s: %s", err.Path, err.Err)
+}
+
+// Unwrap returns the read or parse error
+func (err *TemplateError) Unwrap() error {
+	return err.Err
+}
+
+// TemplateErrors are the errors of all invalid template files
+type TemplateErrors []*TemplateError
+
+// Error returns the messages of all errors on separate lines
+func (errs TemplateErrors) Error() string {
+	messages := make([]string, len(errs))
+	for index, err := range errs {
+		messages[index] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap returns all errors
+func (errs TemplateErrors) Unwrap() []error {
+	unwrapped := make([]error, len(errs))
+	for index, err := range errs {
+		unwrapped[index] = err
+	}
+	return unwrapped
+}
+
+func (errs TemplateErrors) err() error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
@@ -0,0 +1,147 @@
+package giraffe
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// templateSource discovers the template files of a repository
+type templateSource struct {
+	directory   string
+	directories []string
+	fileSystem  fs.FS
+	extensions  []string
+	partials    string
+}
+
+// fingerprint returns a hash of the paths, sizes and modification times of the template files
+func (source *templateSource) fingerprint() uint64 {
+	hash := fnv.New64a()
+	source.walk(func(file *templateFile) error {
+		_, err := fmt.Fprintf(hash, "%s:%d:%d;", file.location, file.info.Size(), file.info.ModTime().UnixNano())
+		return err
+	})
+	return hash.Sum64()
+}
+
+// walk calls fn for every template file of the directories in override order and collects the errors
+func (source *templateSource) walk(fn func(file *templateFile) error) TemplateErrors {
+	errs := TemplateErrors{}
+	extensions := source.extensionSet()
+
+	for _, directory := range source.directoryList() {
+		fsys, root, location := source.open(directory)
+
+		fs.WalkDir(fsys, root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				errs = append(errs, newTemplateError(location(path), err))
+				return nil
+			}
+
+			if entry.IsDir() {
+				return nil
+			}
+
+			rel, ext, err := ext(root, path)
+			if err != nil {
+				errs = append(errs, newTemplateError(location(path), err))
+				return nil
+			}
+
+			if _, ok := extensions[ext]; !ok {
+				return nil
+			}
+
+			info, err := entry.Info()
+			if err != nil {
+				errs = append(errs, newTemplateError(location(path), err))
+				return nil
+			}
+
+			file := &templateFile{
+				fsys:     fsys,
+				path:     path,
+				location: location(path),
+				name:     source.name(filepath.ToSlash(rel), ext),
+				info:     info,
+			}
+
+			if err := fn(file); err != nil {
+				errs = append(errs, newTemplateError(file.location, err))
+			}
+			return nil
+		})
+	}
+
+	return errs
+}
+
+// directoryList returns the directories in override order
+func (source *templateSource) directoryList() []string {
+	directories := append([]string{}, source.directories...)
+	if source.directory != "" || len(directories) == 0 {
+		directories = append(directories, source.directory)
+	}
+	return directories
+}
+
+// extensionSet returns the set of template file extensions
+func (source *templateSource) extensionSet() map[string]struct{} {
+	extensions := map[string]struct{}{}
+	for _, extension := range source.extensions {
+		if extension != "" {
+			extensions[extension] = struct{}{}
+		}
+	}
+
+	if len(extensions) == 0 {
+		extensions[".tmpl"] = struct{}{}
+	}
+	return extensions
+}
+
+// open returns the file system of the directory, the root directory
+// within it and a func that returns the location of a template file in errors
+func (source *templateSource) open(directory string) (fs.FS, string, func(string) string) {
+	if directory == "" {
+		directory = "."
+	}
+
+	if source.fileSystem != nil {
+		return source.fileSystem, directory, func(path string) string { return path }
+	}
+
+	return os.DirFS(directory), ".", func(path string) string {
+		return filepath.Join(directory, filepath.FromSlash(path))
+	}
+}
+
+// name returns the name of the template. The partials are named relative to the partials directory.
+func (source *templateSource) name(rel, ext string) string {
+	if source.partials != "" {
+		if partial, err := filepath.Rel(source.partials, rel); err == nil && !strings.HasPrefix(partial, "..") {
+			return name(filepath.ToSlash(partial), ext)
+		}
+	}
+	return name(rel, ext)
+}
+
+// templateFile is a template file of the file system
+type templateFile struct {
+	fsys fs.FS
+	// path of the file within the file system
+	path string
+	// location of the file that is reported in errors
+	location string
+	// name of the template
+	name string
+	info fs.FileInfo
+}
+
+func (file *templateFile) read() ([]byte, error) {
+	return fs.ReadFile(file.fsys, file.path)
+}
@@ -0,0 +1,84 @@
+package giraffe
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// TextRendererOption configures the TextTemplateRenderer
+type TextRendererOption func(*TextTemplateRenderer)
+
+// WithTextRendererRequest sets the request that is served by the renderer
+func WithTextRendererRequest(request *http.Request) TextRendererOption {
+	return func(renderer *TextTemplateRenderer) {
+		renderer.request = request
+	}
+}
+
+// WithTextRendererErrorHandler sets the handler that writes the error responses of the renderer
+func WithTextRendererErrorHandler(handler ErrorHandler) TextRendererOption {
+	return func(renderer *TextTemplateRenderer) {
+		renderer.errorHandler = handler
+	}
+}
+
+// WithTextContentType sets the content type of the rendered templates, e.g. text/csv. Defaults to ContentText.
+func WithTextContentType(contentType string) TextRendererOption {
+	return func(renderer *TextTemplateRenderer) {
+		renderer.contentType = contentType
+	}
+}
+
+// TextTemplateRenderer renders a text templates of repository
+type TextTemplateRenderer struct {
+	writer       http.ResponseWriter
+	request      *http.Request
+	provider     TextTemplateProvider
+	errorHandler ErrorHandler
+	contentType  string
+}
+
+// Render renders a template. The response is written only when the rendering succeeds.
+func (renderer *TextTemplateRenderer) Render(template string, model Model) error {
+	templates, err := renderer.provider.Provide()
+	if err != nil {
+		renderer.errorf(template, err)
+		return err
+	}
+
+	buffer := getBuffer()
+	defer putBuffer(buffer)
+
+	err = templates.ExecuteTemplate(buffer, template, model)
+	if err != nil {
+		renderer.errorf(template, err)
+		return err
+	}
+
+	setContentType(renderer.writer, renderer.contentType)
+	renderer.writer.Header().Set(ContentLength, strconv.Itoa(buffer.Len()))
+	_, err = buffer.WriteTo(renderer.writer)
+	return err
+}
+
+func (renderer *TextTemplateRenderer) errorf(template string, err error) {
+	problem := newResponseProblem(renderer.writer, renderer.request, http.StatusInternalServerError, fmt.Sprintf("Unable to render '%s' text template", template), err)
+	renderer.errorHandler.HandleError(renderer.writer, renderer.request, problem)
+}
+
+// NewTextTemplateRenderer create a new TextTemplateRenderer for specific provider
+func NewTextTemplateRenderer(writer http.ResponseWriter, provider TextTemplateProvider, options ...TextRendererOption) *TextTemplateRenderer {
+	renderer := &TextTemplateRenderer{
+		writer:       writer,
+		provider:     provider,
+		errorHandler: errorHandler(),
+		contentType:  ContentText,
+	}
+
+	for _, option := range options {
+		option(renderer)
+	}
+
+	return renderer
+}
@@ -0,0 +1,131 @@
+package giraffe_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing/fstest"
+	"text/template"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/svett/giraffe"
+	"github.com/svett/giraffe/fakes"
+)
+
+var _ = Describe("TextTemplateRepository", func() {
+	var repository *giraffe.TextTemplateRepository
+
+	BeforeEach(func() {
+		repository = &giraffe.TextTemplateRepository{
+			Directory:   "emails",
+			Compilation: giraffe.CompileOnce,
+			FileSystem: fstest.MapFS{
+				"emails/welcome.tmpl":       {Data: []byte("Hello, {{.}}! {{signature}}")},
+				"emails/partials/sign.tmpl": {Data: []byte("Bye")},
+			},
+			UtilFuncs: template.FuncMap{
+				"signature": func() string { return "-- giraffe" },
+			},
+		}
+	})
+
+	It("compiles the templates without escaping", func() {
+		templates, err := repository.Provide()
+		Expect(err).NotTo(HaveOccurred())
+
+		recorder := httptest.NewRecorder()
+		Expect(templates.ExecuteTemplate(recorder, "welcome", "<Ben>")).To(Succeed())
+		Expect(recorder.Body.String()).To(Equal("Hello, <Ben>! -- giraffe"))
+	})
+
+	It("names the partials relative to the partials directory", func() {
+		repository.PartialsDirectory = "partials"
+
+		templates, err := repository.Provide()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(templates.Lookup("sign")).NotTo(BeNil())
+	})
+
+	It("returns the parse errors", func() {
+		repository.FileSystem.(fstest.MapFS)["emails/broken.tmpl"] = &fstest.MapFile{Data: []byte("{{.")}
+
+		_, err := repository.Provide()
+		Expect(err).To(HaveOccurred())
+		Expect(err.(giraffe.TemplateErrors)[0].Path).To(Equal("emails/broken.tmpl"))
+		Expect(repository.Validate()).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("TextTemplateRenderer", func() {
+	var (
+		recorder *httptest.ResponseRecorder
+		provider *fakes.FakeTextTemplateProvider
+	)
+
+	BeforeEach(func() {
+		recorder = httptest.NewRecorder()
+
+		templates := template.New("exports")
+		template.Must(templates.New("users").Parse("name\n{{range .}}{{.}}\n{{end}}"))
+
+		provider = new(fakes.FakeTextTemplateProvider)
+		provider.ProvideReturns(templates, nil)
+	})
+
+	It("renders the templates as plain text", func() {
+		renderer := giraffe.NewTextTemplateRenderer(recorder, provider)
+		Expect(renderer.Render("users", []string{"<Ben>", "John"})).To(Succeed())
+		Expect(recorder.Body.String()).To(Equal("name\n<Ben>\nJohn\n"))
+		Expect(recorder.HeaderMap).To(HaveKeyWithValue("Content-Type", []string{"text/plain; charset=UTF-8"}))
+	})
+
+	It("renders the templates with given content type", func() {
+		renderer := giraffe.NewTextTemplateRenderer(recorder, provider, giraffe.WithTextContentType("text/csv"))
+		Expect(renderer.Render("users", []string{"Ben"})).To(Succeed())
+		Expect(recorder.HeaderMap).To(HaveKeyWithValue("Content-Type", []string{"text/csv; charset=UTF-8"}))
+	})
+
+	It("sets the content length", func() {
+		renderer := giraffe.NewTextTemplateRenderer(recorder, provider)
+		Expect(renderer.Render("users", []string{"Ben"})).To(Succeed())
+		Expect(recorder.HeaderMap).To(HaveKeyWithValue("Content-Length", []string{"9"}))
+	})
+
+	Context("when the template execution fails", func() {
+		BeforeEach(func() {
+			templates := template.New("exports")
+			template.Must(templates.New("users").Parse("name\n{{range .}}{{.Name}}\n{{end}}"))
+			provider.ProvideReturns(templates, nil)
+		})
+
+		It("writes clean problem details", func() {
+			renderer := giraffe.NewTextTemplateRenderer(recorder, provider, giraffe.WithTextContentType("text/csv"))
+			Expect(renderer.Render("users", []string{"Ben"})).To(HaveOccurred())
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+			Expect(recorder.HeaderMap).To(HaveKeyWithValue("Content-Type", []string{"application/problem+json; charset=UTF-8"}))
+			Expect(recorder.Body.String()).NotTo(HavePrefix("name"))
+		})
+	})
+
+	Context("when the template provider fails", func() {
+		BeforeEach(func() {
+			provider.ProvideReturns(nil, errors.New("oh no!"))
+		})
+
+		It("writes problem details", func() {
+			renderer := giraffe.NewTextTemplateRenderer(recorder, provider)
+			Expect(renderer.Render("users", nil)).To(MatchError("oh no!"))
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+			Expect(recorder.Body.String()).To(ContainSubstring("Unable to render 'users' text template"))
+		})
+
+		It("uses the error handler", func() {
+			handler := new(fakes.FakeErrorHandler)
+			renderer := giraffe.NewTextTemplateRenderer(recorder, provider, giraffe.WithTextRendererErrorHandler(handler))
+			renderer.Render("users", nil)
+			Expect(handler.HandleErrorCallCount()).To(Equal(1))
+		})
+	})
+})
@@ -0,0 +1,97 @@
+package giraffe
+
+import (
+	"io/fs"
+	"text/template"
+	"time"
+)
+
+//go:generate counterfeiter -o fakes/fake_text_template_provider.go . TextTemplateProvider
+
+// TextTemplateProvider provides a text templates
+type TextTemplateProvider interface {
+	Provide() (*template.Template, error)
+}
+
+// TextTemplateRepository represents a text template repository. It discovers
+// and compiles the templates the same way as HTMLTemplateRepository, but the
+// templates are not escaped. It is useful for emails, CSV exports and
+// configuration files.
+type TextTemplateRepository struct {
+	// cache of the compiled text templates
+	cache templateCache[*template.Template]
+
+	// Directory to load templates
+	Directory string
+	// Directories to load templates before Directory. The templates of a later
+	// directory override the templates with the same name of an earlier one.
+	Directories []string
+	// FileSystem to load the templates from, e.g. embed.FS. The directories are
+	// paths within it. Defaults to the operating system file system.
+	FileSystem fs.FS
+	// FileExtension to parse template files from
+	FileExtension string
+	// FileExtensions to parse template files from in addition to FileExtension.
+	// Defaults to [".tmpl"] when both are empty.
+	FileExtensions []string
+	// Compilation to compile the templates
+	Compilation TemplateCompilation
+	// UtilFuncs is a FuncMap to apply to the template upon compilation
+	UtilFuncs template.FuncMap
+	// PartialsDirectory is a subdirectory of Directory with the partial templates
+	// that are named by their path relative to it. Defaults to none.
+	PartialsDirectory string
+	// PollInterval is the interval between the checks for changed templates when
+	// Compilation is CompileOnChange. Defaults to DefaultPollInterval.
+	PollInterval time.Duration
+}
+
+// Provide returns the repository compiled templates. The read and parse
+// errors of the template files are returned as TemplateErrors. It is safe
+// for concurrent use.
+func (repository *TextTemplateRepository) Provide() (*template.Template, error) {
+	return repository.cache.provide(repository.Compilation, repository.PollInterval, repository.compile, repository.source().fingerprint)
+}
+
+// Validate compiles all template files and returns their errors as TemplateErrors.
+// It does not change the provided templates.
+func (repository *TextTemplateRepository) Validate() error {
+	_, err := repository.compile()
+	return err
+}
+
+// LastError returns the error of the last compilation of the provided templates.
+// When Compilation is CompileOnChange and the changed templates are invalid,
+// Provide keeps returning the last good templates and LastError returns the
+// errors of the changed ones until they are fixed.
+func (repository *TextTemplateRepository) LastError() error {
+	return repository.cache.lastError()
+}
+
+// compile parses the template files into a new set of templates
+func (repository *TextTemplateRepository) compile() (*template.Template, error) {
+	templates := template.New(repository.Directory)
+
+	errs := repository.source().walk(func(file *templateFile) error {
+		buffer, err := file.read()
+		if err != nil {
+			return err
+		}
+
+		_, err = templates.New(file.name).Funcs(repository.UtilFuncs).Parse(string(buffer))
+		return err
+	})
+
+	return templates, errs.err()
+}
+
+// source returns the template files of the repository
+func (repository *TextTemplateRepository) source() *templateSource {
+	return &templateSource{
+		directory:   repository.Directory,
+		directories: repository.Directories,
+		fileSystem:  repository.FileSystem,
+		extensions:  append([]string{repository.FileExtension}, repository.FileExtensions...),
+		partials:    repository.PartialsDirectory,
+	}
+}
@@ -1,12 +1,24 @@
 package giraffe
 
 import (
+	"bytes"
 	"fmt"
 	"net/http"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 )
 
+// maxPooledBufferSize is the capacity above which the buffers are not returned to the pool
+const maxPooledBufferSize = 1 << 20
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return &bytes.Buffer{}
+	},
+}
+
 const (
 	// ContentBinary header value for binary data.
 	ContentBinary = "application/octet-stream"
@@ -18,9 +30,27 @@ const (
 	ContentText = "text/plain"
 	// ContentHTML header value for HTML data.
 	ContentHTML = "text/html"
+	// ContentXML header value for XML data.
+	ContentXML = "application/xml"
+	// ContentNDJSON header value for newline delimited JSON data.
+	ContentNDJSON = "application/x-ndjson"
+	// ContentEventStream header value for server-sent events.
+	ContentEventStream = "text/event-stream"
+	// ContentProblemJSON header value for problem details of an error.
+	ContentProblemJSON = "application/problem+json"
 
 	// ContentType header constant.
 	ContentType = "Content-Type"
+	// Accept header constant.
+	Accept = "Accept"
+	// Vary header constant.
+	Vary = "Vary"
+	// AcceptEncoding header constant.
+	AcceptEncoding = "Accept-Encoding"
+	// ContentEncoding header constant.
+	ContentEncoding = "Content-Encoding"
+	// ContentLength header constant.
+	ContentLength = "Content-Length"
 	// ContentDefaultCharset default character encoding.
 	ContentDefaultCharset = "UTF-8"
 )
@@ -34,6 +64,31 @@ func setContentType(writer http.ResponseWriter, contentType string) {
 	writer.Header().Set(ContentType, contentType)
 }
 
+func addVary(writer http.ResponseWriter, header string) {
+	for _, value := range writer.Header()[Vary] {
+		for _, field := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), header) {
+				return
+			}
+		}
+	}
+
+	writer.Header().Add(Vary, header)
+}
+
+func quality(params []string) float64 {
+	for _, param := range params {
+		key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		if strings.TrimSpace(key) != "q" {
+			continue
+		}
+		if q, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+			return q
+		}
+	}
+	return 1
+}
+
 func name(dir, ext string) string {
 	name := (dir[0 : len(dir)-len(ext)])
 	return name
@@ -51,3 +106,15 @@ func ext(dir, path string) (string, string, error) {
 	}
 	return rel, ext, nil
 }
+
+func getBuffer() *bytes.Buffer {
+	buffer := bufferPool.Get().(*bytes.Buffer)
+	buffer.Reset()
+	return buffer
+}
+
+func putBuffer(buffer *bytes.Buffer) {
+	if buffer.Cap() <= maxPooledBufferSize {
+		bufferPool.Put(buffer)
+	}
+}